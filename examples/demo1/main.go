@@ -6,10 +6,15 @@ import (
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
 )
 
-func SampleMethod(args *moduleTypes.ArgsSampleMethod) sampleResult.SampleResult {
+func SampleMethod(args *moduleTypes.ArgsSampleMethod, env *moduleTypes.Env) sampleResult.SampleResult {
+	increment := big.NewInt(1)
+	if env != nil && env.Increment != nil {
+		increment = env.Increment
+	}
+
 	result := "0"
 	if num, ok := new(big.Int).SetString(args.Arg, 10); ok {
-		result = num.Add(num, big.NewInt(1)).String()
+		result = num.Add(num, increment).String()
 	}
 	return sampleResult.SampleResult{Value: result}
 }
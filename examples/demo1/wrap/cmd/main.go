@@ -5,15 +5,13 @@ import (
 	"github.com/consideritdone/polywrap-go/polywrap"
 )
 
+func init() {
+	polywrap.Register("sampleMethod", module.SampleMethodWrapped)
+}
+
 //export _wrap_invoke
 func _wrap_invoke(methodSize, argsSize, envSize uint32) bool {
-	args := polywrap.WrapInvokeArgs(methodSize, argsSize)
-
-	if args.Method == "sampleMethod" {
-		return polywrap.WrapInvoke(args, envSize, module.SampleMethodWrapped)
-	} else {
-		return polywrap.WrapInvoke(args, envSize, nil)
-	}
+	return polywrap.HandleInvoke(methodSize, argsSize, envSize)
 }
 
 func main() {
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/examples/demo1/wrap/moduleTypes"
+	"github.com/consideritdone/polywrap-go/examples/demo1/wrap/sampleResult"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/consideritdone/polywrap-go/polywrap/polywraptest"
+)
+
+func TestSampleMethod(t *testing.T) {
+	h := polywraptest.New(_wrap_invoke)
+
+	tests := []struct {
+		name   string
+		arg    string
+		env    interface{}
+		result string
+	}{
+		{name: "without env", arg: "41", result: "42"},
+		{name: "with env", arg: "41", env: moduleTypes.Env{Increment: big.NewInt(9)}, result: "50"},
+		{name: "not a number", arg: "x", result: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result sampleResult.SampleResult
+			args := moduleTypes.ArgsSampleMethod{Arg: tt.arg}
+			if err := h.Invoke("sampleMethod", args, tt.env, &result); err != nil {
+				t.Fatalf("Invoke failed: %v", err)
+			}
+			if result.Value != tt.result {
+				t.Errorf("Bad result, got: %q, want: %q", result.Value, tt.result)
+			}
+		})
+	}
+}
+
+func TestSampleMethodJSON(t *testing.T) {
+	h := polywraptest.New(_wrap_invoke)
+
+	result, err := h.InvokeJSON("sampleMethod", []byte(`{"arg":"1"}`), nil)
+	if err != nil {
+		t.Fatalf("InvokeJSON failed: %v", err)
+	}
+	if want := `{"value":"2"}`; string(result) != want {
+		t.Errorf("Bad result, got: %s, want: %s", result, want)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	h := polywraptest.New(_wrap_invoke)
+
+	err := h.Invoke("otherMethod", nil, nil, nil)
+	var invokeErr *polywraptest.InvokeError
+	want := "Could not find invoke function \"otherMethod\", available methods: sampleMethod"
+	if !errors.As(err, &invokeErr) || invokeErr.Message != want {
+		t.Errorf("Bad error, got: %v, want: %s", err, want)
+	}
+}
@@ -24,6 +24,8 @@ func deserializeSampleMethodArgs(argsBuf []byte) *moduleTypes.ArgsSampleMethod {
 			_arg = reader.ReadString()
 			_argSet = true
 			reader.Context().Pop()
+		} else {
+			reader.Skip()
 		}
 		reader.Context().Pop()
 	}
@@ -38,8 +40,12 @@ func deserializeSampleMethodArgs(argsBuf []byte) *moduleTypes.ArgsSampleMethod {
 }
 
 func serializeSampleMethodResult(result sampleResult.SampleResult) []byte {
+	sizerContext := msgpack.NewContext("Serializing (sizing) module-type: sampleMethod")
+	sizer := msgpack.NewWriteSizer(sizerContext)
+	writeSampleMethodResult(sizer, result)
+
 	context := msgpack.NewContext("Serializing (encoding) module-type: sampleMethod")
-	encoder := msgpack.NewWriteEncoder(context)
+	encoder := msgpack.NewWriteEncoderWithSize(context, sizer.Length())
 	writeSampleMethodResult(encoder, result)
 
 	return encoder.Buffer()
@@ -1,11 +1,21 @@
 package module
 
-import "github.com/consideritdone/polywrap-go/examples/demo1"
+import (
+	"github.com/consideritdone/polywrap-go/examples/demo1"
+	"github.com/consideritdone/polywrap-go/examples/demo1/wrap/moduleTypes"
+	"github.com/consideritdone/polywrap-go/polywrap"
+)
 
 func SampleMethodWrapped(argsBuf []byte, envSize uint32) []byte {
+	var env *moduleTypes.Env
+	if envSize > 0 {
+		value := polywrap.RequireEnv("sampleMethod", envSize, moduleTypes.ReadEnv)
+		env = &value
+	}
+
 	args := deserializeSampleMethodArgs(argsBuf)
 
-	result := demo1.SampleMethod(args)
+	result := demo1.SampleMethod(args, env)
 
 	return serializeSampleMethodResult(result)
 }
@@ -0,0 +1,30 @@
+package moduleTypes
+
+import (
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+)
+
+func ReadEnv(reader msgpack.Read) Env {
+	numFields := reader.ReadMapLength()
+
+	var _increment *big.Int
+
+	for i := numFields; i > 0; i-- {
+		field := reader.ReadString()
+
+		reader.Context().Push(field, "unknown", "searching for property type")
+		if field == "increment" {
+			reader.Context().Push(field, "*big.Int", "type found, reading property")
+			_increment = reader.ReadBigInt()
+			reader.Context().Pop()
+		} else {
+			reader.Skip()
+		}
+		reader.Context().Pop()
+	}
+
+	return Env{
+		Increment: _increment,
+	}
+}
@@ -1,5 +1,11 @@
 package moduleTypes
 
+import "github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+
 type ArgsSampleMethod struct {
 	Arg string
 }
+
+type Env struct {
+	Increment *big.Int
+}
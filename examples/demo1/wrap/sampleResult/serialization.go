@@ -3,8 +3,12 @@ package sampleResult
 import "github.com/consideritdone/polywrap-go/polywrap/msgpack"
 
 func serializeSampleResult(args SampleResult) []byte {
+	sizerContext := msgpack.NewContext("Serializing (sizing) object-type: SampleResult")
+	sizer := msgpack.NewWriteSizer(sizerContext)
+	writeSampleResult(sizer, args)
+
 	context := msgpack.NewContext("Serializing (encoding) object-type: SampleResult")
-	encoder := msgpack.NewWriteEncoder(context)
+	encoder := msgpack.NewWriteEncoderWithSize(context, sizer.Length())
 	writeSampleResult(encoder, args)
 
 	return encoder.Buffer()
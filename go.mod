@@ -1,5 +1,5 @@
 module github.com/consideritdone/polywrap-go
 
-go 1.17
+go 1.18
 
 require github.com/valyala/fastjson v1.6.3
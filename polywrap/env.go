@@ -1,16 +1,56 @@
 package polywrap
 
-import "unsafe"
+import (
+	"errors"
 
-//go:wasm-module wrap
-//export __wrap_load_env
-func __wrap_load_env(envPtr uint32)
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack"
+)
+
+// ErrEnvNotSet is returned when the host did not pass an env to the
+// invocation.
+var ErrEnvNotSet = errors.New("Environment is not set")
 
 func WrapLoadEnv(envSize uint32) []byte {
 	envBuf := make([]byte, envSize)
-	envPtr := unsafe.Pointer(&envBuf)
 
-	__wrap_load_env(*(*uint32)(envPtr))
+	currentHost().LoadEnv(envBuf)
 
 	return envBuf
 }
+
+// LoadEnv loads the env of the invocation and reads it with fn, usually the
+// generated ReadEnv function of the module. It returns ErrEnvNotSet if the
+// host passed no env, and a *msgpack.DecodeError if the env is malformed.
+func LoadEnv[T any](envSize uint32, fn func(reader msgpack.Read) T) (T, error) {
+	var env T
+	if envSize == 0 {
+		return env, ErrEnvNotSet
+	}
+	context := msgpack.NewContext("Deserializing env-type: Env")
+	reader := msgpack.NewReadDecoderWithOptions(context, WrapLoadEnv(envSize), msgpack.ReadOptions{})
+	env = fn(reader)
+	return env, reader.Finish()
+}
+
+// RequireEnv is LoadEnv for methods that require an env. It panics if the
+// env is missing or malformed.
+func RequireEnv[T any](method string, envSize uint32, fn func(reader msgpack.Read) T) T {
+	env, err := LoadEnv(envSize, fn)
+	if err == ErrEnvNotSet {
+		panic("Environment is not set, and it is required by method '" + method + "'")
+	}
+	if err != nil {
+		panic(err.Error())
+	}
+	return env
+}
+
+// UnmarshalEnv loads the env of the invocation and decodes it into v with
+// msgpack.Unmarshal, for Env structs that have no generated reader. It
+// returns ErrEnvNotSet if the host passed no env.
+func UnmarshalEnv(envSize uint32, v interface{}) error {
+	if envSize == 0 {
+		return ErrEnvNotSet
+	}
+	return msgpack.Unmarshal(WrapLoadEnv(envSize), v)
+}
@@ -0,0 +1,49 @@
+package polywrap
+
+// Host is the interface of the imports a wrapper calls on its host. The
+// methods mirror the wasm imports of the "wrap" module, with Go buffers in
+// place of pointers: the host fills the buffers it is given and reads the
+// others.
+//
+// Wasm builds talk to the real host. Native builds have no host until one is
+// set with SetHost, so wrappers and their generated glue can be unit-tested
+// with the standard Go toolchain.
+type Host interface {
+	InvokeArgs(method, args []byte)
+	InvokeResult(result []byte)
+	InvokeError(message string)
+
+	Subinvoke(uri, method string, args []byte) bool
+	SubinvokeResultLen() uint32
+	SubinvokeResult(result []byte)
+	SubinvokeErrorLen() uint32
+	SubinvokeError(message []byte)
+
+	SubinvokeImplementation(interfaceUri, implUri, method string, args []byte) bool
+	SubinvokeImplementationResultLen() uint32
+	SubinvokeImplementationResult(result []byte)
+	SubinvokeImplementationErrorLen() uint32
+	SubinvokeImplementationError(message []byte)
+
+	LoadEnv(env []byte)
+}
+
+var host = defaultHost
+
+// SetHost makes the Wrap functions call h and returns the previous host. A
+// nil h restores the default host of the build.
+func SetHost(h Host) Host {
+	previous := host
+	if h == nil {
+		h = defaultHost
+	}
+	host = h
+	return previous
+}
+
+func currentHost() Host {
+	if host == nil {
+		panic("polywrap: no host in native builds, set one with SetHost")
+	}
+	return host
+}
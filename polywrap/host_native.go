@@ -0,0 +1,6 @@
+//go:build !wasm
+// +build !wasm
+
+package polywrap
+
+var defaultHost Host
@@ -0,0 +1,243 @@
+package polywrap
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack"
+)
+
+// testHost answers the imports from its fields and records what the wrapper
+// sent.
+type testHost struct {
+	method, args []byte
+	env          []byte
+
+	result []byte
+	error  string
+
+	subinvoked []string
+	subResult  []byte
+	subError   string
+}
+
+func (h *testHost) InvokeArgs(method, args []byte) {
+	copy(method, h.method)
+	copy(args, h.args)
+}
+
+func (h *testHost) InvokeResult(result []byte) {
+	h.result = append([]byte(nil), result...)
+}
+
+func (h *testHost) InvokeError(message string) {
+	h.error = message
+}
+
+func (h *testHost) Subinvoke(uri, method string, args []byte) bool {
+	h.subinvoked = append(h.subinvoked, uri, method, string(args))
+	return h.subError == ""
+}
+
+func (h *testHost) SubinvokeResultLen() uint32 {
+	return uint32(len(h.subResult))
+}
+
+func (h *testHost) SubinvokeResult(result []byte) {
+	copy(result, h.subResult)
+}
+
+func (h *testHost) SubinvokeErrorLen() uint32 {
+	return uint32(len(h.subError))
+}
+
+func (h *testHost) SubinvokeError(message []byte) {
+	copy(message, h.subError)
+}
+
+func (h *testHost) SubinvokeImplementation(interfaceUri, implUri, method string, args []byte) bool {
+	h.subinvoked = append(h.subinvoked, interfaceUri, implUri, method, string(args))
+	return h.subError == ""
+}
+
+func (h *testHost) SubinvokeImplementationResultLen() uint32 {
+	return h.SubinvokeResultLen()
+}
+
+func (h *testHost) SubinvokeImplementationResult(result []byte) {
+	h.SubinvokeResult(result)
+}
+
+func (h *testHost) SubinvokeImplementationErrorLen() uint32 {
+	return h.SubinvokeErrorLen()
+}
+
+func (h *testHost) SubinvokeImplementationError(message []byte) {
+	h.SubinvokeError(message)
+}
+
+func (h *testHost) LoadEnv(env []byte) {
+	copy(env, h.env)
+}
+
+func withHost(t *testing.T, h Host) {
+	previous := SetHost(h)
+	t.Cleanup(func() { SetHost(previous) })
+}
+
+func TestWrapInvoke(t *testing.T) {
+	h := &testHost{method: []byte("method"), args: []byte("args")}
+	withHost(t, h)
+
+	args := WrapInvokeArgs(6, 4)
+	if args.Method != "method" || string(args.Args) != "args" {
+		t.Fatalf("Bad invoke args, got: %q %q, want: \"method\" \"args\"", args.Method, args.Args)
+	}
+
+	ok := WrapInvoke(args, 0, func(argsBuf []byte, envSize uint32) []byte {
+		return append([]byte("result of "), argsBuf...)
+	})
+	if !ok || string(h.result) != "result of args" {
+		t.Errorf("Bad invoke result, got: %v %q, want: true \"result of args\"", ok, h.result)
+	}
+
+	if ok := WrapInvoke(args, 0, nil); ok {
+		t.Errorf("Bad invoke of a missing method, got: true, want: false")
+	}
+	if want := "Could not find invoke function \"method\""; h.error != want {
+		t.Errorf("Bad invoke error, got: %q, want: %q", h.error, want)
+	}
+}
+
+func TestWrapSubinvoke(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       *testHost
+		subinvoke  func() ([]byte, error)
+		subinvoked []string
+		result     []byte
+		err        error
+	}{
+		{
+			name: "subinvoke",
+			host: &testHost{subResult: []byte("result")},
+			subinvoke: func() ([]byte, error) {
+				return WrapSubinvoke("wrap://ens/a.eth", "method", []byte("args"))
+			},
+			subinvoked: []string{"wrap://ens/a.eth", "method", "args"},
+			result:     []byte("result"),
+		},
+		{
+			name: "subinvoke error",
+			host: &testHost{subError: "failed"},
+			subinvoke: func() ([]byte, error) {
+				return WrapSubinvoke("wrap://ens/a.eth", "method", nil)
+			},
+			subinvoked: []string{"wrap://ens/a.eth", "method", ""},
+			err:        errors.New("failed"),
+		},
+		{
+			name: "implementation subinvoke",
+			host: &testHost{subResult: []byte("result")},
+			subinvoke: func() ([]byte, error) {
+				return WrapSubinvokeImplementation("wrap://ens/i.eth", "wrap://ens/a.eth", "method", []byte("args"))
+			},
+			subinvoked: []string{"wrap://ens/i.eth", "wrap://ens/a.eth", "method", "args"},
+			result:     []byte("result"),
+		},
+		{
+			name: "implementation subinvoke error",
+			host: &testHost{subError: "failed"},
+			subinvoke: func() ([]byte, error) {
+				return WrapSubinvokeImplementation("wrap://ens/i.eth", "wrap://ens/a.eth", "method", nil)
+			},
+			subinvoked: []string{"wrap://ens/i.eth", "wrap://ens/a.eth", "method", ""},
+			err:        errors.New("failed"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withHost(t, tt.host)
+			result, err := tt.subinvoke()
+			if strings.Join(tt.host.subinvoked, " ") != strings.Join(tt.subinvoked, " ") {
+				t.Errorf("Bad subinvoke, got: %q, want: %q", tt.host.subinvoked, tt.subinvoked)
+			}
+			if !bytes.Equal(result, tt.result) {
+				t.Errorf("Bad result, got: %q, want: %q", result, tt.result)
+			}
+			if (err == nil) != (tt.err == nil) || err != nil && err.Error() != tt.err.Error() {
+				t.Errorf("Bad error, got: %v, want: %v", err, tt.err)
+			}
+		})
+	}
+}
+
+type testEnv struct {
+	Prop string
+}
+
+func readTestEnv(reader msgpack.Read) testEnv {
+	var env testEnv
+	for i := reader.ReadMapLength(); i > 0; i-- {
+		if reader.ReadString() == "prop" {
+			env.Prop = reader.ReadString()
+		}
+	}
+	return env
+}
+
+func TestLoadEnv(t *testing.T) {
+	encoder := msgpack.NewWriteEncoder(msgpack.NewContext(""))
+	encoder.WriteMapLength(1)
+	encoder.WriteString("prop")
+	encoder.WriteString("value")
+	envBuf := encoder.Buffer()
+
+	withHost(t, &testHost{env: envBuf})
+
+	env, err := LoadEnv(uint32(len(envBuf)), readTestEnv)
+	if err != nil || env.Prop != "value" {
+		t.Errorf("Bad env, got: %+v %v, want: {Prop:value} <nil>", env, err)
+	}
+	if _, err := LoadEnv(0, readTestEnv); err != ErrEnvNotSet {
+		t.Errorf("Bad error, got: %v, want: %v", err, ErrEnvNotSet)
+	}
+	if env := RequireEnv("method", uint32(len(envBuf)), readTestEnv); env.Prop != "value" {
+		t.Errorf("Bad env, got: %+v, want: {Prop:value}", env)
+	}
+
+	var unmarshaled testEnv
+	if err := UnmarshalEnv(uint32(len(envBuf)), &unmarshaled); err != nil || unmarshaled.Prop != "value" {
+		t.Errorf("Bad env, got: %+v %v, want: {Prop:value} <nil>", unmarshaled, err)
+	}
+	if err := UnmarshalEnv(0, &unmarshaled); err != ErrEnvNotSet {
+		t.Errorf("Bad error, got: %v, want: %v", err, ErrEnvNotSet)
+	}
+
+	withHost(t, &testHost{env: envBuf[:len(envBuf)-1]})
+	if _, err := LoadEnv(uint32(len(envBuf)-1), readTestEnv); err == nil {
+		t.Errorf("Bad error, got: <nil>, want: a decode error")
+	}
+}
+
+func TestNoHost(t *testing.T) {
+	if runtime.Compiler == "tinygo" {
+		t.Log("Skipping due tinygo limitations")
+		return
+	}
+	if defaultHost != nil {
+		t.Skip("the build has a default host")
+	}
+	withHost(t, nil)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("No panic without a host")
+		}
+	}()
+	WrapInvokeArgs(0, 0)
+}
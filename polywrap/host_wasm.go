@@ -0,0 +1,154 @@
+//go:build wasm
+// +build wasm
+
+package polywrap
+
+import "github.com/consideritdone/polywrap-go/polywrap/internal/memory"
+
+// Invoke
+
+//go:wasm-module wrap
+//export __wrap_invoke_args
+func __wrap_invoke_args(methodPtr, argsPtr uint32)
+
+//go:wasm-module wrap
+//export __wrap_invoke_result
+func __wrap_invoke_result(ptr, len uint32)
+
+//go:wasm-module wrap
+//export __wrap_invoke_error
+func __wrap_invoke_error(ptr, len uint32)
+
+// Subinvoke
+
+//go:wasm-module wrap
+//export __wrap_subinvoke
+func __wrap_subinvoke(uriPtr, uriLen, methodPtr, methodLen, argsPtr, argsLen uint32) bool
+
+//go:wasm-module wrap
+//export __wrap_subinvoke_result_len
+func __wrap_subinvoke_result_len() uint32
+
+//go:wasm-module wrap
+//export __wrap_subinvoke_result
+func __wrap_subinvoke_result(ptr uint32)
+
+//go:wasm-module wrap
+//export __wrap_subinvoke_error_len
+func __wrap_subinvoke_error_len() uint32
+
+//go:wasm-module wrap
+//export __wrap_subinvoke_error
+func __wrap_subinvoke_error(ptr uint32)
+
+// Implementation Subinvoke
+
+//go:wasm-module wrap
+//export __wrap_subinvokeImplementation
+func __wrap_subinvokeImplementation(interfaceUriPtr, interfaceUriLen, implUriPtr, implUriLen, methodPtr, methodLen, argsPtr, argsLen uint32) bool
+
+//go:wasm-module wrap
+//export __wrap_subinvokeImplementation_result_len
+func __wrap_subinvokeImplementation_result_len() uint32
+
+//go:wasm-module wrap
+//export __wrap_subinvokeImplementation_result
+func __wrap_subinvokeImplementation_result(ptr uint32)
+
+//go:wasm-module wrap
+//export __wrap_subinvokeImplementation_error_len
+func __wrap_subinvokeImplementation_error_len() uint32
+
+//go:wasm-module wrap
+//export __wrap_subinvokeImplementation_error
+func __wrap_subinvokeImplementation_error(ptr uint32)
+
+// Env
+
+//go:wasm-module wrap
+//export __wrap_load_env
+func __wrap_load_env(envPtr uint32)
+
+var defaultHost Host = wasmHost{}
+
+// wasmHost calls the wasm imports, pinning the buffers for each call.
+type wasmHost struct{}
+
+func (wasmHost) InvokeArgs(method, args []byte) {
+	var pins memory.Pinner
+	__wrap_invoke_args(pins.BytesPtr(method), pins.BytesPtr(args))
+	pins.Unpin()
+}
+
+func (wasmHost) InvokeResult(result []byte) {
+	var pins memory.Pinner
+	__wrap_invoke_result(pins.BytesPtr(result), uint32(len(result)))
+	pins.Unpin()
+}
+
+func (wasmHost) InvokeError(message string) {
+	var pins memory.Pinner
+	__wrap_invoke_error(pins.StringPtr(message), uint32(len(message)))
+	pins.Unpin()
+}
+
+func (wasmHost) Subinvoke(uri, method string, args []byte) bool {
+	var pins memory.Pinner
+	defer pins.Unpin()
+	return __wrap_subinvoke(pins.StringPtr(uri), uint32(len(uri)), pins.StringPtr(method), uint32(len(method)),
+		pins.BytesPtr(args), uint32(len(args)))
+}
+
+func (wasmHost) SubinvokeResultLen() uint32 {
+	return __wrap_subinvoke_result_len()
+}
+
+func (wasmHost) SubinvokeResult(result []byte) {
+	var pins memory.Pinner
+	__wrap_subinvoke_result(pins.BytesPtr(result))
+	pins.Unpin()
+}
+
+func (wasmHost) SubinvokeErrorLen() uint32 {
+	return __wrap_subinvoke_error_len()
+}
+
+func (wasmHost) SubinvokeError(message []byte) {
+	var pins memory.Pinner
+	__wrap_subinvoke_error(pins.BytesPtr(message))
+	pins.Unpin()
+}
+
+func (wasmHost) SubinvokeImplementation(interfaceUri, implUri, method string, args []byte) bool {
+	var pins memory.Pinner
+	defer pins.Unpin()
+	return __wrap_subinvokeImplementation(pins.StringPtr(interfaceUri), uint32(len(interfaceUri)),
+		pins.StringPtr(implUri), uint32(len(implUri)), pins.StringPtr(method), uint32(len(method)),
+		pins.BytesPtr(args), uint32(len(args)))
+}
+
+func (wasmHost) SubinvokeImplementationResultLen() uint32 {
+	return __wrap_subinvokeImplementation_result_len()
+}
+
+func (wasmHost) SubinvokeImplementationResult(result []byte) {
+	var pins memory.Pinner
+	__wrap_subinvokeImplementation_result(pins.BytesPtr(result))
+	pins.Unpin()
+}
+
+func (wasmHost) SubinvokeImplementationErrorLen() uint32 {
+	return __wrap_subinvokeImplementation_error_len()
+}
+
+func (wasmHost) SubinvokeImplementationError(message []byte) {
+	var pins memory.Pinner
+	__wrap_subinvokeImplementation_error(pins.BytesPtr(message))
+	pins.Unpin()
+}
+
+func (wasmHost) LoadEnv(env []byte) {
+	var pins memory.Pinner
+	__wrap_load_env(pins.BytesPtr(env))
+	pins.Unpin()
+}
@@ -0,0 +1,50 @@
+//go:build wasm
+// +build wasm
+
+// Package memory passes Go buffers to the host across the wasm ABI, where a
+// buffer is the uint32 offset of its first byte in the linear memory.
+//
+// The pointers are only valid while the buffers are pinned:
+//
+//	var pins memory.Pinner
+//	__wrap_subinvoke(pins.StringPtr(uri), uint32(len(uri)), ...)
+//	pins.Unpin()
+package memory
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+// Pinner keeps the buffers it returned pointers for alive until Unpin. The
+// zero value is ready to use.
+type Pinner struct {
+	pinned []interface{}
+}
+
+// BytesPtr pins b and returns a pointer to its first byte, or 0 if b is
+// empty. The host may write up to len(b) bytes there.
+func (p *Pinner) BytesPtr(b []byte) uint32 {
+	if len(b) == 0 {
+		return 0
+	}
+	p.pinned = append(p.pinned, b)
+	return uint32(uintptr(unsafe.Pointer(&b[0])))
+}
+
+// StringPtr pins s and returns a pointer to its first byte, or 0 if s is
+// empty. The host must not write there.
+func (p *Pinner) StringPtr(s string) uint32 {
+	if len(s) == 0 {
+		return 0
+	}
+	p.pinned = append(p.pinned, s)
+	return uint32(uintptr(stringData(s)))
+}
+
+// Unpin releases the buffers pinned by p. It must be called after the host
+// call that received the pointers has returned.
+func (p *Pinner) Unpin() {
+	runtime.KeepAlive(p.pinned)
+	p.pinned = nil
+}
@@ -0,0 +1,67 @@
+//go:build wasm
+// +build wasm
+
+package memory
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+// load returns the size bytes at ptr in the linear memory, like the host sees
+// them.
+func load(ptr, size uint32) []byte {
+	if ptr == 0 || size == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Add(nil, ptr)), size)
+}
+
+func TestPinnerEmpty(t *testing.T) {
+	var pins Pinner
+	defer pins.Unpin()
+
+	if ptr := pins.BytesPtr(nil); ptr != 0 {
+		t.Errorf("Bad pointer, got: %d, want: 0", ptr)
+	}
+	if ptr := pins.BytesPtr([]byte{}); ptr != 0 {
+		t.Errorf("Bad pointer, got: %d, want: 0", ptr)
+	}
+	if ptr := pins.StringPtr(""); ptr != 0 {
+		t.Errorf("Bad pointer, got: %d, want: 0", ptr)
+	}
+	if data := load(0, 0); data != nil {
+		t.Errorf("Bad data, got: %v, want: nil", data)
+	}
+}
+
+func TestPinnerBytes(t *testing.T) {
+	var pins Pinner
+	defer pins.Unpin()
+
+	// the host writes into the buffer, like __wrap_invoke_args does
+	buf := make([]byte, 5)
+	ptr := pins.BytesPtr(buf[1:])
+	copy(load(ptr, 4), "data")
+	if !bytes.Equal(buf, []byte("\x00data")) {
+		t.Errorf("Bad buffer, got: %q, want: %q", buf, "\x00data")
+	}
+}
+
+func TestPinnerString(t *testing.T) {
+	var pins Pinner
+	defer pins.Unpin()
+
+	uris := []string{"wrap://ens/first.eth", "wrap://ens/second.eth"}
+	ptrs := []uint32{pins.StringPtr(uris[0]), pins.StringPtr(uris[1][7:])}
+	runtime.GC()
+
+	if data := string(load(ptrs[0], uint32(len(uris[0])))); data != uris[0] {
+		t.Errorf("Bad data, got: %q, want: %q", data, uris[0])
+	}
+	if data := string(load(ptrs[1], 3)); data != "ens" {
+		t.Errorf("Bad data, got: %q, want: %q", data, "ens")
+	}
+}
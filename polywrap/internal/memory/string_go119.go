@@ -0,0 +1,13 @@
+//go:build wasm && !go1.20
+// +build wasm,!go1.20
+
+package memory
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+func stringData(s string) unsafe.Pointer {
+	return unsafe.Pointer((*reflect.StringHeader)(unsafe.Pointer(&s)).Data)
+}
@@ -0,0 +1,10 @@
+//go:build wasm && go1.20
+// +build wasm,go1.20
+
+package memory
+
+import "unsafe"
+
+func stringData(s string) unsafe.Pointer {
+	return unsafe.Pointer(unsafe.StringData(s))
+}
@@ -1,20 +1,8 @@
 package polywrap
 
-import "unsafe"
-
-//go:wasm-module wrap
-//export __wrap_invoke_args
-func __wrap_invoke_args(methodPtr, argsPtr uint32)
-
-//go:wasm-module wrap
-//export __wrap_invoke_result
-func __wrap_invoke_result(ptr, len uint32)
-
-//go:wasm-module wrap
-//export __wrap_invoke_error
-func __wrap_invoke_error(ptr, len uint32)
-
-type invokeFunction func(argsBuf []byte, envSize uint32) []byte
+// InvokeFunction handles the invocation of a method: it decodes the args,
+// loads the env if envSize is not 0 and returns the encoded result.
+type InvokeFunction func(argsBuf []byte, envSize uint32) []byte
 
 type InvokeArgs struct {
 	Method string
@@ -23,12 +11,9 @@ type InvokeArgs struct {
 
 func WrapInvokeArgs(methodSize, argsSize uint32) InvokeArgs {
 	methodBuf := make([]byte, methodSize)
-	methodPtr := unsafe.Pointer(&methodBuf)
-
 	argsBuf := make([]byte, argsSize)
-	argsPtr := unsafe.Pointer(&argsBuf)
 
-	__wrap_invoke_args(*(*uint32)(methodPtr), *(*uint32)(argsPtr))
+	currentHost().InvokeArgs(methodBuf, argsBuf)
 
 	method := string(methodBuf)
 
@@ -38,19 +23,17 @@ func WrapInvokeArgs(methodSize, argsSize uint32) InvokeArgs {
 	}
 }
 
-func WrapInvoke(args InvokeArgs, envSize uint32, fn invokeFunction) bool {
+func WrapInvoke(args InvokeArgs, envSize uint32, fn InvokeFunction) bool {
 	if fn != nil {
 		result := fn(args.Args, envSize)
-		resultPtr := unsafe.Pointer(&result)
 
-		__wrap_invoke_result(*(*uint32)(resultPtr), uint32(len(result)))
+		currentHost().InvokeResult(result)
 
 		return true
 	} else {
 		message := "Could not find invoke function \"" + args.Method + "\""
-		messagePtr := unsafe.Pointer(&message)
 
-		__wrap_invoke_error(*(*uint32)(messagePtr), uint32(len(message)))
+		currentHost().InvokeError(message)
 
 		return false
 	}
@@ -0,0 +1,67 @@
+package polywrap
+
+import (
+	"sort"
+	"strings"
+)
+
+// Module maps the method names of a wrapper to their invoke functions. The
+// zero value is an empty module.
+type Module struct {
+	methods map[string]InvokeFunction
+}
+
+// DefaultModule is the Module used by Register and HandleInvoke.
+var DefaultModule = new(Module)
+
+// Register makes fn handle the invocations of the method name. It panics if
+// fn is nil or the method is already registered.
+func (m *Module) Register(name string, fn InvokeFunction) {
+	if fn == nil {
+		panic("polywrap: nil invoke function for method \"" + name + "\"")
+	}
+	if _, ok := m.methods[name]; ok {
+		panic("polywrap: method \"" + name + "\" is already registered")
+	}
+	if m.methods == nil {
+		m.methods = make(map[string]InvokeFunction)
+	}
+	m.methods[name] = fn
+}
+
+// Methods returns the names of the registered methods in sorted order.
+func (m *Module) Methods() []string {
+	names := make([]string, 0, len(m.methods))
+	for name := range m.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleInvoke reads the invocation and calls the invoke function of its
+// method. It is the body of the _wrap_invoke export of a wrapper.
+func (m *Module) HandleInvoke(methodSize, argsSize, envSize uint32) bool {
+	args := WrapInvokeArgs(methodSize, argsSize)
+
+	fn, ok := m.methods[args.Method]
+	if !ok {
+		message := "Could not find invoke function \"" + args.Method + "\""
+		if len(m.methods) > 0 {
+			message += ", available methods: " + strings.Join(m.Methods(), ", ")
+		}
+		currentHost().InvokeError(message)
+		return false
+	}
+	return WrapInvoke(args, envSize, fn)
+}
+
+// Register registers a method of DefaultModule.
+func Register(name string, fn InvokeFunction) {
+	DefaultModule.Register(name, fn)
+}
+
+// HandleInvoke handles an invocation with DefaultModule.
+func HandleInvoke(methodSize, argsSize, envSize uint32) bool {
+	return DefaultModule.HandleInvoke(methodSize, argsSize, envSize)
+}
@@ -0,0 +1,84 @@
+package polywrap
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestModule(t *testing.T) {
+	var m Module
+	m.Register("b", func(argsBuf []byte, envSize uint32) []byte {
+		return []byte("b")
+	})
+	m.Register("a", func(argsBuf []byte, envSize uint32) []byte {
+		return append([]byte("a "), argsBuf...)
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		ok     bool
+		result string
+		error  string
+	}{
+		{name: "method", method: "a", ok: true, result: "a args"},
+		{name: "other method", method: "b", ok: true, result: "b"},
+		{
+			name:   "unknown method",
+			method: "c",
+			error:  "Could not find invoke function \"c\", available methods: a, b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &testHost{method: []byte(tt.method), args: []byte("args")}
+			withHost(t, h)
+
+			ok := m.HandleInvoke(uint32(len(tt.method)), 4, 0)
+			if ok != tt.ok || string(h.result) != tt.result || h.error != tt.error {
+				t.Errorf("Bad invoke, got: %v %q %q, want: %v %q %q", ok, h.result, h.error, tt.ok, tt.result, tt.error)
+			}
+		})
+	}
+}
+
+func TestModuleEmpty(t *testing.T) {
+	var m Module
+	h := &testHost{method: []byte("a")}
+	withHost(t, h)
+
+	if ok := m.HandleInvoke(1, 0, 0); ok {
+		t.Errorf("Bad invoke of an empty module, got: true, want: false")
+	}
+	if want := "Could not find invoke function \"a\""; h.error != want {
+		t.Errorf("Bad invoke error, got: %q, want: %q", h.error, want)
+	}
+}
+
+func TestModuleRegisterPanics(t *testing.T) {
+	if runtime.Compiler == "tinygo" {
+		t.Log("Skipping due tinygo limitations")
+		return
+	}
+	fn := func(argsBuf []byte, envSize uint32) []byte { return nil }
+
+	tests := []struct {
+		name     string
+		register func(m *Module)
+	}{
+		{name: "nil function", register: func(m *Module) { m.Register("a", nil) }},
+		{name: "duplicate method", register: func(m *Module) { m.Register("a", fn); m.Register("a", fn) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("No panic")
+				}
+			}()
+			tt.register(&Module{})
+		})
+	}
+}
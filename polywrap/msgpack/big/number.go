@@ -0,0 +1,432 @@
+// This file implements arbitrary-precision decimal numbers.
+
+package big
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A Number represents an arbitrary-precision decimal number, the BigNumber
+// scalar of Polywrap schemas. Its value is unscaled * 10**exp, where unscaled
+// has no trailing zero digits. The zero value for a Number represents 0.
+//
+// Addition, subtraction and multiplication are exact. Division and rounding
+// take the number of decimal places of the result and a RoundingMode.
+//
+// Operations take pointer arguments and set the receiver like the ones of
+// Int do. Add and Sub align the digits of their operands, they panic if that
+// takes more than 2*MaxNumberExp+1 digits, which never happens for numbers
+// parsed by SetString.
+type Number struct {
+	unscaled Int
+	exp      int
+}
+
+// MaxNumberExp bounds the numbers SetString accepts: their non-zero digits
+// must lie between 10**-MaxNumberExp and 10**MaxNumberExp, so a number read
+// from untrusted input has at most 2*MaxNumberExp+1 digits.
+const MaxNumberExp = 10000
+
+// RoundingMode selects how a result is rounded to the requested number of
+// decimal places. The modes and their order are the ones of bignumber.js.
+type RoundingMode byte
+
+const (
+	RoundUp        RoundingMode = iota // away from zero
+	RoundDown                          // towards zero
+	RoundCeil                          // towards +Infinity
+	RoundFloor                         // towards -Infinity
+	RoundHalfUp                        // to nearest, ties away from zero
+	RoundHalfDown                      // to nearest, ties towards zero
+	RoundHalfEven                      // to nearest, ties to even
+	RoundHalfCeil                      // to nearest, ties towards +Infinity
+	RoundHalfFloor                     // to nearest, ties towards -Infinity
+)
+
+var roundingModeNames = [...]string{
+	"RoundUp", "RoundDown", "RoundCeil", "RoundFloor",
+	"RoundHalfUp", "RoundHalfDown", "RoundHalfEven", "RoundHalfCeil", "RoundHalfFloor",
+}
+
+func (mode RoundingMode) String() string {
+	if int(mode) < len(roundingModeNames) {
+		return roundingModeNames[mode]
+	}
+	return "RoundingMode(" + strconv.Itoa(int(mode)) + ")"
+}
+
+// NewNumber allocates and returns a new Number set to x * 10**exp.
+func NewNumber(x int64, exp int) *Number {
+	z := new(Number)
+	z.unscaled.SetInt64(x)
+	z.exp = exp
+	return z.norm()
+}
+
+// norm removes the trailing zero digits of z.unscaled.
+func (z *Number) norm() *Number {
+	if z.unscaled.Sign() == 0 {
+		z.exp = 0
+		return z
+	}
+	var q, r Int
+	for z.unscaled.TrailingZeroBits() > 0 {
+		q.QuoRem(&z.unscaled, intTen, &r)
+		if r.Sign() != 0 {
+			break
+		}
+		z.unscaled.Set(&q)
+		z.exp++
+	}
+	return z
+}
+
+var intTen = NewInt(10)
+
+// pow10 returns 10**n for n >= 0.
+func pow10(n int) *Int {
+	return new(Int).Exp(intTen, NewInt(int64(n)), nil)
+}
+
+// Set sets z to x and returns z.
+func (z *Number) Set(x *Number) *Number {
+	if z != x {
+		z.unscaled.Set(&x.unscaled)
+		z.exp = x.exp
+	}
+	return z
+}
+
+// SetInt sets z to x and returns z.
+func (z *Number) SetInt(x *Int) *Number {
+	z.unscaled.Set(x)
+	z.exp = 0
+	return z.norm()
+}
+
+// SetInt64 sets z to x and returns z.
+func (z *Number) SetInt64(x int64) *Number {
+	z.unscaled.SetInt64(x)
+	z.exp = 0
+	return z.norm()
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of x.
+func (x *Number) Sign() int {
+	return x.unscaled.Sign()
+}
+
+// IsInt reports whether x is an integer.
+func (x *Number) IsInt() bool {
+	return x.exp >= 0
+}
+
+// Int sets z to x truncated towards zero and returns z. If z is nil a new Int
+// is allocated.
+func (x *Number) Int(z *Int) *Int {
+	if z == nil {
+		z = new(Int)
+	}
+	if x.exp >= 0 {
+		return z.Mul(&x.unscaled, pow10(x.exp))
+	}
+	return z.Quo(&x.unscaled, pow10(-x.exp))
+}
+
+// Neg sets z to -x and returns z.
+func (z *Number) Neg(x *Number) *Number {
+	z.Set(x)
+	z.unscaled.Neg(&z.unscaled)
+	return z
+}
+
+// Abs sets z to |x| and returns z.
+func (z *Number) Abs(x *Number) *Number {
+	z.Set(x)
+	z.unscaled.Abs(&z.unscaled)
+	return z
+}
+
+// top returns the exponent of the leading digit of x, which must not be 0.
+func (x *Number) top() int {
+	// |x| has n or n+1 digits
+	n := int(float64(x.unscaled.BitLen()-1)*math.Log10(2)) + 1
+	if n > 1 && x.unscaled.CmpAbs(pow10(n-1)) < 0 {
+		n--
+	} else if x.unscaled.CmpAbs(pow10(n)) >= 0 {
+		n++
+	}
+	return x.exp + n - 1
+}
+
+// checkAlign panics if aligning x and y, which must not be 0, takes more than
+// 2*MaxNumberExp+1 digits.
+func checkAlign(x, y *Number) {
+	top, exp := x.top(), x.exp
+	if t := y.top(); t > top {
+		top = t
+	}
+	if y.exp < exp {
+		exp = y.exp
+	}
+	if top-exp >= 2*MaxNumberExp+1 {
+		panic("big: Number operands are too far apart to align")
+	}
+}
+
+// align returns the unscaled values of x and y scaled to their smaller
+// exponent, and that exponent.
+func align(x, y *Number) (*Int, *Int, int) {
+	switch {
+	case x.exp > y.exp:
+		return new(Int).Mul(&x.unscaled, pow10(x.exp-y.exp)), &y.unscaled, y.exp
+	case x.exp < y.exp:
+		return &x.unscaled, new(Int).Mul(&y.unscaled, pow10(y.exp-x.exp)), x.exp
+	}
+	return &x.unscaled, &y.unscaled, x.exp
+}
+
+// Cmp compares x and y and returns -1 if x < y, 0 if x == y and +1 if x > y.
+func (x *Number) Cmp(y *Number) int {
+	if xs, ys := x.Sign(), y.Sign(); xs != ys || xs == 0 {
+		switch {
+		case xs < ys:
+			return -1
+		case xs > ys:
+			return 1
+		}
+		return 0
+	} else if xt, yt := x.top(), y.top(); xt != yt {
+		// numbers of different magnitude compare without aligning them
+		if (xt > yt) == (xs > 0) {
+			return 1
+		}
+		return -1
+	}
+	a, b, _ := align(x, y)
+	return a.Cmp(b)
+}
+
+// Add sets z to the sum x+y and returns z.
+func (z *Number) Add(x, y *Number) *Number {
+	switch {
+	case x.Sign() == 0:
+		return z.Set(y)
+	case y.Sign() == 0:
+		return z.Set(x)
+	}
+	checkAlign(x, y)
+	a, b, exp := align(x, y)
+	z.unscaled.Add(a, b)
+	z.exp = exp
+	return z.norm()
+}
+
+// Sub sets z to the difference x-y and returns z.
+func (z *Number) Sub(x, y *Number) *Number {
+	switch {
+	case x.Sign() == 0:
+		return z.Neg(y)
+	case y.Sign() == 0:
+		return z.Set(x)
+	}
+	checkAlign(x, y)
+	a, b, exp := align(x, y)
+	z.unscaled.Sub(a, b)
+	z.exp = exp
+	return z.norm()
+}
+
+// Mul sets z to the product x*y and returns z.
+func (z *Number) Mul(x, y *Number) *Number {
+	z.unscaled.Mul(&x.unscaled, &y.unscaled)
+	z.exp = x.exp + y.exp
+	return z.norm()
+}
+
+// Quo sets z to the quotient x/y rounded to places decimal places with mode
+// and returns z. A negative places rounds to a multiple of 10**-places. If y
+// is 0, a division-by-zero run-time panic occurs.
+func (z *Number) Quo(x, y *Number, places int, mode RoundingMode) *Number {
+	if y.Sign() == 0 {
+		panic("division by zero")
+	}
+	// x/y * 10**places = unscaled(x)/unscaled(y) * 10**shift
+	num, den := new(Int).Set(&x.unscaled), new(Int).Set(&y.unscaled)
+	if shift := x.exp - y.exp + places; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+	z.unscaled.Set(roundQuo(num, den, mode))
+	z.exp = -places
+	return z.norm()
+}
+
+// Round sets z to x rounded to places decimal places with mode and returns
+// z. A negative places rounds to a multiple of 10**-places.
+func (z *Number) Round(x *Number, places int, mode RoundingMode) *Number {
+	if -x.exp <= places {
+		return z.Set(x)
+	}
+	z.unscaled.Set(roundQuo(new(Int).Set(&x.unscaled), pow10(-x.exp-places), mode))
+	z.exp = -places
+	return z.norm()
+}
+
+// roundQuo returns num/den rounded to an integer with mode.
+func roundQuo(num, den *Int, mode RoundingMode) *Int {
+	q, r := new(Int).QuoRem(num, den, new(Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	negative := num.Sign() != den.Sign()
+	// half compares the remainder to half of the divisor
+	half := new(Int).Lsh(r, 1).CmpAbs(den)
+
+	var away bool
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundDown:
+		away = false
+	case RoundCeil:
+		away = !negative
+	case RoundFloor:
+		away = negative
+	case RoundHalfUp:
+		away = half >= 0
+	case RoundHalfDown:
+		away = half > 0
+	case RoundHalfEven:
+		away = half > 0 || half == 0 && q.Bit(0) == 1
+	case RoundHalfCeil:
+		away = half > 0 || half == 0 && !negative
+	case RoundHalfFloor:
+		away = half > 0 || half == 0 && negative
+	default:
+		panic("invalid rounding mode " + mode.String())
+	}
+	if !away {
+		return q
+	}
+	if negative {
+		return q.Sub(q, intOne)
+	}
+	return q.Add(q, intOne)
+}
+
+// String returns the decimal representation of x in the format of the
+// toString method of bignumber.js: exponential notation like "1.5e+21" or
+// "1e-7" when the exponent of the leading digit is at least 21 or at most -7,
+// and fixed-point notation like "-0.000123" otherwise.
+func (x *Number) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	digits := new(Int).Abs(&x.unscaled).String()
+	exp := len(digits) - 1 + x.exp
+
+	var sb strings.Builder
+	if x.Sign() < 0 {
+		sb.WriteByte('-')
+	}
+	switch {
+	case exp <= -7 || exp >= 21:
+		sb.WriteString(digits[:1])
+		if len(digits) > 1 {
+			sb.WriteByte('.')
+			sb.WriteString(digits[1:])
+		}
+		sb.WriteByte('e')
+		if exp >= 0 {
+			sb.WriteByte('+')
+		}
+		sb.WriteString(strconv.Itoa(exp))
+	case x.exp >= 0:
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", x.exp))
+	case exp >= 0:
+		sb.WriteString(digits[:exp+1])
+		sb.WriteByte('.')
+		sb.WriteString(digits[exp+1:])
+	default:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", -exp-1))
+		sb.WriteString(digits)
+	}
+	return sb.String()
+}
+
+// SetString sets z to the value of s and returns z and a boolean indicating
+// success. s is a decimal number with an optional sign, fraction and
+// exponent, like "-12.5", ".5" or "1.5e+21". NaN and Infinity are not
+// supported, and neither are numbers beyond the bounds of MaxNumberExp. On
+// failure the value of z is undefined but the returned value is nil.
+func (z *Number) SetString(s string) (*Number, bool) {
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e < -1e9 || e > 1e9 {
+			return nil, false
+		}
+		mantissa, exp = s[:i], e
+	}
+
+	sign := ""
+	if mantissa != "" && (mantissa[0] == '+' || mantissa[0] == '-') {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+	integer, fraction := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		integer, fraction = mantissa[:i], mantissa[i+1:]
+	}
+	if integer == "" && fraction == "" || !isDigits(integer) || !isDigits(fraction) {
+		return nil, false
+	}
+
+	// trailing zeros move to the exponent, and the bounds are checked before
+	// the digits are parsed
+	digits := strings.TrimRight(integer+fraction, "0")
+	exp += len(integer) - len(digits)
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return z.SetInt64(0), true
+	}
+	if exp < -MaxNumberExp || exp+len(digits)-1 > MaxNumberExp {
+		return nil, false
+	}
+	if _, ok := z.unscaled.SetString(sign+digits, 10); !ok {
+		return nil, false
+	}
+	z.exp = exp
+	return z, true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x *Number) MarshalText() (text []byte, err error) {
+	if x == nil {
+		return []byte("<nil>"), nil
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (z *Number) UnmarshalText(text []byte) error {
+	if _, ok := z.SetString(string(text)); !ok {
+		return fmt.Errorf("math/big: cannot unmarshal %q into a *big.Number", text)
+	}
+	return nil
+}
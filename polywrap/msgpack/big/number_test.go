@@ -0,0 +1,243 @@
+package big
+
+import (
+	"runtime"
+	"testing"
+)
+
+func newNumber(t *testing.T, s string) *Number {
+	x, ok := new(Number).SetString(s)
+	if !ok {
+		t.Fatalf("SetString(%q) failed", s)
+	}
+	return x
+}
+
+var numberStringTests = []struct {
+	in  string
+	out string
+	ok  bool
+}{
+	{in: "0", out: "0", ok: true},
+	{in: "-0", out: "0", ok: true},
+	{in: "+1", out: "1", ok: true},
+	{in: "-12.500", out: "-12.5", ok: true},
+	{in: ".5", out: "0.5", ok: true},
+	{in: "5.", out: "5", ok: true},
+	{in: "007", out: "7", ok: true},
+	{in: "1e3", out: "1000", ok: true},
+	{in: "1E-3", out: "0.001", ok: true},
+	{in: "0.000001", out: "0.000001", ok: true},
+	{in: "0.0000001", out: "1e-7", ok: true},
+	{in: "-0.00000012", out: "-1.2e-7", ok: true},
+	{in: "100000000000000000000", out: "100000000000000000000", ok: true},
+	{in: "1000000000000000000000", out: "1e+21", ok: true},
+	{in: "123456789012345678901234", out: "1.23456789012345678901234e+23", ok: true},
+	{in: "1.5e+21", out: "1.5e+21", ok: true},
+	{in: "0e100", out: "0", ok: true},
+	{in: ""},
+	{in: "-"},
+	{in: "."},
+	{in: "e5"},
+	{in: "1e"},
+	{in: "1e+"},
+	{in: "--1"},
+	{in: "1.2.3"},
+	{in: "1_000"},
+	{in: "0x10"},
+	{in: "NaN"},
+	{in: "Infinity"},
+	{in: "1e10000000000"},
+	{in: "1e10000", out: "1e+10000", ok: true},
+	{in: "1e-10000", out: "1e-10000", ok: true},
+	{in: "12e9999", out: "1.2e+10000", ok: true},
+	{in: "100e9998", out: "1e+10000", ok: true},
+	{in: "1e10001"},
+	{in: "1e-10001"},
+	{in: "123e9999"},
+	{in: "0.01e-9999"},
+	{in: "1e100000000"},
+	{in: "0e100000000", out: "0", ok: true},
+}
+
+func TestNumberSetString(t *testing.T) {
+	for _, test := range numberStringTests {
+		x, ok := new(Number).SetString(test.in)
+		if ok != test.ok {
+			t.Errorf("SetString(%q) ok = %v; want %v", test.in, ok, test.ok)
+			continue
+		}
+		if !ok {
+			if x != nil {
+				t.Errorf("SetString(%q) = %v; want nil", test.in, x)
+			}
+			continue
+		}
+		if s := x.String(); s != test.out {
+			t.Errorf("SetString(%q).String() = %q; want %q", test.in, s, test.out)
+		}
+	}
+}
+
+func TestNumberMarshalText(t *testing.T) {
+	x := newNumber(t, "-1.25e-10")
+	text, err := x.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var y Number
+	if err := y.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	if x.Cmp(&y) != 0 {
+		t.Errorf("got %v; want %v", &y, x)
+	}
+	if err := y.UnmarshalText([]byte("1.x")); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+func TestNumberArith(t *testing.T) {
+	tests := []struct {
+		x, y            string
+		sum, diff, prod string
+		cmp             int
+	}{
+		{"0", "0", "0", "0", "0", 0},
+		{"1", "0.1", "1.1", "0.9", "0.1", 1},
+		{"0.1", "0.2", "0.3", "-0.1", "0.02", -1},
+		{"-1.5", "1.5", "0", "-3", "-2.25", -1},
+		{"1e+30", "1e-30", "1.000000000000000000000000000000000000000000000000000000000001e+30", "9.99999999999999999999999999999999999999999999999999999999999e+29", "1", 1},
+		{"123.45", "123.450", "246.9", "0", "15239.9025", 0},
+		{"-2", "-10", "-12", "8", "20", 1},
+		{"0", "-1e-10000", "-1e-10000", "1e-10000", "0", 1},
+		{"1e+10000", "0", "1e+10000", "1e+10000", "0", 1},
+		{"-99", "-1e+3", "-1099", "901", "99000", 1},
+		{"0.5", "-1e+3", "-999.5", "1000.5", "-500", 1},
+		{"9.9", "10", "19.9", "-0.1", "99", -1},
+	}
+	for _, test := range tests {
+		x, y := newNumber(t, test.x), newNumber(t, test.y)
+		if got := new(Number).Add(x, y).String(); got != test.sum {
+			t.Errorf("%s + %s = %s; want %s", test.x, test.y, got, test.sum)
+		}
+		if got := new(Number).Sub(x, y).String(); got != test.diff {
+			t.Errorf("%s - %s = %s; want %s", test.x, test.y, got, test.diff)
+		}
+		if got := new(Number).Mul(x, y).String(); got != test.prod {
+			t.Errorf("%s * %s = %s; want %s", test.x, test.y, got, test.prod)
+		}
+		if got := x.Cmp(y); got != test.cmp {
+			t.Errorf("Cmp(%s, %s) = %d; want %d", test.x, test.y, got, test.cmp)
+		}
+	}
+}
+
+func TestNumberAlignPanics(t *testing.T) {
+	if runtime.Compiler == "tinygo" {
+		t.Log("Skipping due tinygo limitations")
+		return
+	}
+	x := new(Number).Mul(newNumber(t, "1e10000"), newNumber(t, "1e10000"))
+	y := newNumber(t, "1e-10000")
+
+	// numbers of any magnitude compare
+	if got := x.Cmp(y); got != 1 {
+		t.Errorf("Cmp(1e+20000, 1e-10000) = %d; want 1", got)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Add(1e+20000, 1e-10000) did not panic")
+		}
+	}()
+	new(Number).Add(x, y)
+}
+
+func TestNumberRound(t *testing.T) {
+	tests := []struct {
+		x      string
+		places int
+		mode   RoundingMode
+		out    string
+	}{
+		{"2.1", 0, RoundUp, "3"},
+		{"-2.1", 0, RoundUp, "-3"},
+		{"2.9", 0, RoundDown, "2"},
+		{"-2.9", 0, RoundDown, "-2"},
+		{"-2.1", 0, RoundCeil, "-2"},
+		{"2.1", 0, RoundCeil, "3"},
+		{"-2.1", 0, RoundFloor, "-3"},
+		{"2.9", 0, RoundFloor, "2"},
+		{"2.5", 0, RoundHalfUp, "3"},
+		{"-2.5", 0, RoundHalfUp, "-3"},
+		{"2.5", 0, RoundHalfDown, "2"},
+		{"2.51", 0, RoundHalfDown, "3"},
+		{"2.5", 0, RoundHalfEven, "2"},
+		{"3.5", 0, RoundHalfEven, "4"},
+		{"-2.5", 0, RoundHalfEven, "-2"},
+		{"-2.5", 0, RoundHalfCeil, "-2"},
+		{"2.5", 0, RoundHalfCeil, "3"},
+		{"2.5", 0, RoundHalfFloor, "2"},
+		{"-2.5", 0, RoundHalfFloor, "-3"},
+		{"0.004", 2, RoundHalfUp, "0"},
+		{"-0.004", 2, RoundUp, "-0.01"},
+		{"1.23456", 3, RoundHalfUp, "1.235"},
+		{"1.2", 3, RoundDown, "1.2"},
+		{"1250", -2, RoundHalfEven, "1200"},
+		{"1350", -2, RoundHalfEven, "1400"},
+	}
+	for _, test := range tests {
+		x := newNumber(t, test.x)
+		if got := new(Number).Round(x, test.places, test.mode).String(); got != test.out {
+			t.Errorf("Round(%s, %d, %v) = %s; want %s", test.x, test.places, test.mode, got, test.out)
+		}
+	}
+}
+
+func TestNumberQuo(t *testing.T) {
+	tests := []struct {
+		x, y   string
+		places int
+		mode   RoundingMode
+		out    string
+	}{
+		{"1", "3", 20, RoundHalfUp, "0.33333333333333333333"},
+		{"2", "3", 20, RoundHalfUp, "0.66666666666666666667"},
+		{"-2", "3", 2, RoundHalfUp, "-0.67"},
+		{"-2", "3", 2, RoundDown, "-0.66"},
+		{"1", "-8", 2, RoundHalfEven, "-0.12"},
+		{"1", "-8", 2, RoundHalfUp, "-0.13"},
+		{"1e+30", "1e-30", 0, RoundDown, "1e+60"},
+		{"1e-30", "3", 5, RoundUp, "0.00001"},
+		{"10", "4", 0, RoundHalfEven, "2"},
+		{"123", "0.1", 0, RoundDown, "1230"},
+		{"0", "7", 5, RoundUp, "0"},
+	}
+	for _, test := range tests {
+		x, y := newNumber(t, test.x), newNumber(t, test.y)
+		if got := new(Number).Quo(x, y, test.places, test.mode).String(); got != test.out {
+			t.Errorf("Quo(%s, %s, %d, %v) = %s; want %s", test.x, test.y, test.places, test.mode, got, test.out)
+		}
+	}
+}
+
+func TestNumberInt(t *testing.T) {
+	tests := []struct {
+		x   string
+		out string
+	}{
+		{"0", "0"},
+		{"1e3", "1000"},
+		{"-12.9", "-12"},
+		{"0.5", "0"},
+	}
+	for _, test := range tests {
+		x := newNumber(t, test.x)
+		if got := x.Int(nil).String(); got != test.out {
+			t.Errorf("Int(%s) = %s; want %s", test.x, got, test.out)
+		}
+		if x.IsInt() != (x.Cmp(new(Number).SetInt(x.Int(nil))) == 0) {
+			t.Errorf("IsInt(%s) = %v", test.x, x.IsInt())
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package msgpack
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+)
+
+// Canonicalize rewrites data in the form a WriteEncoder produces with
+// WriteOptions.Canonical. Non-negative integers take the unsigned formats,
+// other items keep their msgpack types: float32 is not widened and empty
+// binaries and arrays are not turned into nil. Maps wrapped
+// in GenericMap extensions are canonicalized as well, other extension
+// payloads are copied. Duplicate map keys and trailing bytes are errors.
+func Canonicalize(data []byte) ([]byte, error) {
+	context := NewContext("Canonicalizing msgpack")
+	reader := NewReadDecoderWithOptions(context, data, ReadOptions{})
+	writer := NewWriteEncoderWithOptions(context, WriteOptions{Size: int32(len(data)), Canonical: true})
+
+	canonicalizeValue(reader, writer)
+	reader.checkEnd()
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return writer.Buffer(), nil
+}
+
+func canonicalizeValue(reader *ReadDecoder, writer *WriteEncoder) {
+	f := reader.peekFormat()
+	if reader.err != nil || reader.view.Fill(1) == 0 {
+		// reports the end of input
+		reader.readFormat()
+		return
+	}
+	switch {
+	case f == format.NIL:
+		reader.readFormat()
+		writer.WriteNil()
+	case f == format.TRUE || f == format.FALSE:
+		writer.WriteBool(reader.ReadBool())
+	case isFixedInt(uint8(f)), f == format.UINT8, f == format.UINT16, f == format.UINT32, f == format.UINT64:
+		writer.WriteU64(reader.ReadU64())
+	case isNegativeFixedInt(uint8(f)), f == format.INT8, f == format.INT16, f == format.INT32, f == format.INT64:
+		writer.WriteI64(reader.ReadI64())
+	case f == format.FLOAT32:
+		writer.WriteFloat32(reader.ReadF32())
+	case f == format.FLOAT64:
+		writer.WriteFloat64(reader.ReadF64())
+	case isFixedString(uint8(f)), f == format.STR8, f == format.STR16, f == format.STR32:
+		writer.WriteString(reader.ReadString())
+	case f == format.BIN8, f == format.BIN16, f == format.BIN32:
+		ln := reader.ReadBytesLength()
+		data := reader.readBytes(ln)
+		writer.WriteBytesLength(ln)
+		writer.view.WriteBytes(data)
+	case isFixedArray(uint8(f)), f == format.ARRAY16, f == format.ARRAY32:
+		size := reader.ReadArrayLength()
+		if !reader.enter() {
+			return
+		}
+		writer.WriteArrayLength(size)
+		for i := uint32(0); i < size && reader.err == nil; i++ {
+			canonicalizeValue(reader, writer)
+		}
+		reader.leave()
+	case isFixedMap(uint8(f)), f == format.MAP16, f == format.MAP32:
+		canonicalizeMap(reader, writer)
+	case isExt(f):
+		canonicalizeExt(reader, writer)
+	default:
+		reader.readFormat()
+		reader.unexpected("Unknown format "+format.ToString(f), format.ERROR)
+	}
+}
+
+func canonicalizeMap(reader *ReadDecoder, writer *WriteEncoder) {
+	type entry struct {
+		key, value []byte
+		offset     int
+	}
+
+	size := reader.ReadMapLength()
+	if !reader.enter() {
+		return
+	}
+	defer reader.leave()
+	entries := make([]entry, 0, reader.capacity(size))
+	for i := uint32(0); i < size && reader.err == nil; i++ {
+		offset := reader.view.Offset()
+		key := NewWriteEncoderWithOptions(writer.context, writer.options)
+		canonicalizeValue(reader, key)
+		value := NewWriteEncoderWithOptions(writer.context, writer.options)
+		canonicalizeValue(reader, value)
+		entries = append(entries, entry{key: key.Buffer(), value: value.Buffer(), offset: offset})
+	}
+	if reader.err != nil {
+		return
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	writer.WriteMapLength(size)
+	for i := range entries {
+		if i > 0 && bytes.Equal(entries[i-1].key, entries[i].key) {
+			reader.fail("Duplicate map key", format.ERROR, format.Format(entries[i].key[0]), entries[i].offset)
+			return
+		}
+		writer.view.WriteBytes(entries[i].key)
+		writer.view.WriteBytes(entries[i].value)
+	}
+}
+
+func canonicalizeExt(reader *ReadDecoder, writer *WriteEncoder) {
+	ln := reader.readExtLength()
+	f, offset := reader.format, reader.offset
+	typ := reader.readInt8()
+	if reader.err != nil {
+		return
+	}
+	if typ != ExtGenericMap {
+		writer.WriteExt(typ, reader.readBytes(ln))
+		return
+	}
+
+	start := reader.view.Offset()
+	value := NewWriteEncoderWithOptions(writer.context, writer.options)
+	canonicalizeMap(reader, value)
+	if reader.err == nil && reader.view.Offset()-start != int(ln) {
+		reader.fail("GenericMap extension length mismatch", format.EXT32, f, offset)
+		return
+	}
+	writer.writeExtGenericMapLength(uint32(len(value.Buffer())))
+	writer.view.WriteBytes(value.Buffer())
+}
@@ -0,0 +1,155 @@
+package msgpack
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalHeaders(t *testing.T) {
+	cases := []struct {
+		name  string
+		write func(writer Write)
+		want  []byte
+	}{
+		{"positive int", func(w Write) { w.WriteI64(200) }, []byte{0xcc, 0xc8}},
+		{"positive int16", func(w Write) { w.WriteI32(40000) }, []byte{0xcd, 0x9c, 0x40}},
+		{"negative int", func(w Write) { w.WriteI64(-200) }, []byte{0xd1, 0xff, 0x38}},
+		{"bytes length", func(w Write) { w.WriteBytesLength(255) }, []byte{0xc4, 0xff}},
+		{"map", func(w Write) {
+			w.WriteMap(map[interface{}]interface{}{"b": 1, "a": 2, "c": 3}, func(w Write, key interface{}, value interface{}) {
+				w.WriteString(key.(string))
+				w.WriteI32(int32(value.(int)))
+			})
+		}, []byte{0x83, 0xa1, 'a', 0x02, 0xa1, 'b', 0x01, 0xa1, 'c', 0x03}},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			options := WriteOptions{Canonical: true}
+			encoder := NewWriteEncoderWithOptions(NewContext(""), options)
+			tcase.write(encoder)
+			if !bytes.Equal(encoder.Buffer(), tcase.want) {
+				t.Errorf("Bad value, got: %x, want: %x", encoder.Buffer(), tcase.want)
+			}
+
+			sizer := NewWriteSizerWithOptions(NewContext(""), options)
+			tcase.write(sizer)
+			if int(sizer.Length()) != len(tcase.want) {
+				t.Errorf("Bad size, got: %v, want: %v", sizer.Length(), len(tcase.want))
+			}
+		})
+	}
+}
+
+func TestMarshalCanonicalIsDeterministic(t *testing.T) {
+	value := map[string]interface{}{}
+	for _, key := range []string{"one", "two", "three", "four", "five", "six", "seven", "eight"} {
+		value[key] = map[interface{}]interface{}{key: int64(len(key)), int64(len(key)): key}
+	}
+
+	first, err := MarshalWithOptions(value, WriteOptions{Canonical: true})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		data, err := MarshalWithOptions(value, WriteOptions{Canonical: true})
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		if !bytes.Equal(data, first) {
+			t.Fatalf("Encoding is not deterministic, got: %x, want: %x", data, first)
+		}
+
+		plain, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		canonical, err := Canonicalize(plain)
+		if err != nil {
+			t.Fatalf("Canonicalize error: %v", err)
+		}
+		if !bytes.Equal(canonical, first) {
+			t.Fatalf("Bad canonical form, got: %x, want: %x", canonical, first)
+		}
+	}
+}
+
+func TestCanonicalI64RoundTrip(t *testing.T) {
+	type S struct{ A int64 }
+	for _, value := range []int64{math.MaxUint32 + 1, 1 << 40, math.MaxInt64, -1 << 40} {
+		encoder := NewWriteEncoderWithOptions(NewContext(""), WriteOptions{Canonical: true})
+		encoder.WriteI64(value)
+		reader := NewReadDecoderWithOptions(NewContext(""), encoder.Buffer(), ReadOptions{})
+		if actual := reader.ReadI64(); reader.Err() != nil || actual != value {
+			t.Errorf("Bad value, got: %v (%v), want: %v", actual, reader.Err(), value)
+		}
+
+		data, err := MarshalWithOptions(S{A: value}, WriteOptions{Canonical: true})
+		if err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+		var actual S
+		if err := Unmarshal(data, &actual); err != nil || actual.A != value {
+			t.Errorf("Bad value, got: %v (%v), want: %v", actual.A, err, value)
+		}
+	}
+
+	reader := NewReadDecoderWithOptions(NewContext(""), []byte{0xcf, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, ReadOptions{})
+	reader.ReadI64()
+	if err := reader.Err(); err == nil || !strings.Contains(err.Error(), "int64 overflow") {
+		t.Errorf("Bad error, got: %v, want: int64 overflow", err)
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"int16 to fixint", []byte{0xd1, 0x00, 0x05}, []byte{0x05}},
+		{"int16 to uint8", []byte{0xd1, 0x00, 0xc8}, []byte{0xcc, 0xc8}},
+		{"int64 to uint64", []byte{0xd3, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00},
+			[]byte{0xcf, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00}},
+		{"float32 kept", []byte{0xca, 0x3f, 0x80, 0x00, 0x00}, []byte{0xca, 0x3f, 0x80, 0x00, 0x00}},
+		{"empty array kept", []byte{0xdc, 0x00, 0x00}, []byte{0x90}},
+		{"empty bin kept", []byte{0xc5, 0x00, 0x00}, []byte{0xc4, 0x00}},
+		{"str8 to fixstr", []byte{0xd9, 0x01, 'a'}, []byte{0xa1, 'a'}},
+		{"sorted map", []byte{0x82, 0xa1, 'b', 0x01, 0xa1, 'a', 0x02}, []byte{0x82, 0xa1, 'a', 0x02, 0xa1, 'b', 0x01}},
+		{"generic map", []byte{0xc8, 0x00, 0x07, 0x01, 0x82, 0xa1, 'b', 0x01, 0xa1, 'a', 0x02},
+			[]byte{0xc7, 0x07, 0x01, 0x82, 0xa1, 'a', 0x02, 0xa1, 'b', 0x01}},
+		{"ext", []byte{0xc7, 0x01, 0x05, 0xff}, []byte{0xd4, 0x05, 0xff}},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			actual, err := Canonicalize(tcase.data)
+			if err != nil {
+				t.Fatalf("Canonicalize error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tcase.want) {
+				t.Errorf("Bad value, got: %x, want: %x", actual, tcase.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	cases := map[string][]byte{
+		"duplicate key":  {0x82, 0xa1, 'a', 0x01, 0xd9, 0x01, 'a', 0x02},
+		"trailing bytes": {0x01, 0x02},
+		"truncated":      {0x92, 0x01},
+		"bad generic":    {0xc7, 0x02, 0x01, 0x81, 0x01},
+	}
+
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Canonicalize(data); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
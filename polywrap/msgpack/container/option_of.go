@@ -0,0 +1,90 @@
+package container
+
+// OptionOf is the typed counterpart of Option.
+type OptionOf[T any] struct {
+	isValue bool
+	value   T
+}
+
+func SomeOf[T any](value T) OptionOf[T] {
+	return OptionOf[T]{
+		isValue: true,
+		value:   value,
+	}
+}
+
+func NoneOf[T any]() OptionOf[T] {
+	return OptionOf[T]{}
+}
+
+// OptionFrom converts an Option to an OptionOf. It returns false if the value
+// of o is not a T.
+func OptionFrom[T any](o Option) (OptionOf[T], bool) {
+	if o.IsNone() {
+		return NoneOf[T](), true
+	}
+	v, ok := o.value.(T)
+	if !ok {
+		return NoneOf[T](), false
+	}
+	return SomeOf(v), true
+}
+
+func (o OptionOf[T]) IsSome() bool {
+	return o.isValue
+}
+
+func (o OptionOf[T]) IsNone() bool {
+	return !o.isValue
+}
+
+func (o OptionOf[T]) Get() (T, bool) {
+	return o.value, o.isValue
+}
+
+func (o OptionOf[T]) MustGet() T {
+	if o.IsNone() {
+		panic(ErrEmptyOption)
+	}
+	return o.value
+}
+
+func (o OptionOf[T]) UnwrapOr(fallback T) T {
+	if o.IsNone() {
+		return fallback
+	}
+	return o.value
+}
+
+// Untyped converts o to an Option.
+func (o OptionOf[T]) Untyped() Option {
+	if o.IsNone() {
+		return None()
+	}
+	return Some(o.value)
+}
+
+// MapOption returns fn applied to the value of o, or None.
+func MapOption[T, U any](o OptionOf[T], fn func(T) U) OptionOf[U] {
+	if o.IsNone() {
+		return NoneOf[U]()
+	}
+	return SomeOf(fn(o.value))
+}
+
+// FlatMapOption returns the option returned by fn for the value of o, or
+// None.
+func FlatMapOption[T, U any](o OptionOf[T], fn func(T) OptionOf[U]) OptionOf[U] {
+	if o.IsNone() {
+		return NoneOf[U]()
+	}
+	return fn(o.value)
+}
+
+// MatchOption returns onSome applied to the value of o, or onNone.
+func MatchOption[T, U any](o OptionOf[T], onSome func(T) U, onNone func() U) U {
+	if o.IsNone() {
+		return onNone()
+	}
+	return onSome(o.value)
+}
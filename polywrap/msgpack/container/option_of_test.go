@@ -0,0 +1,73 @@
+package container
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestOptionOfSome(t *testing.T) {
+	compare(t, SomeOf(42), OptionOf[int]{isValue: true, value: 42})
+	compare(t, SomeOf(42).IsSome(), true)
+	compare(t, SomeOf(42).IsNone(), false)
+}
+
+func TestOptionOfNone(t *testing.T) {
+	compare(t, NoneOf[int](), OptionOf[int]{isValue: false, value: 0})
+	compare(t, NoneOf[int]().IsSome(), false)
+	compare(t, NoneOf[int]().IsNone(), true)
+}
+
+func TestOptionOfGet(t *testing.T) {
+	v1, ok1 := SomeOf(42).Get()
+	v2, ok2 := NoneOf[int]().Get()
+	compare(t, v1, 42)
+	compare(t, ok1, true)
+	compare(t, v2, 0)
+	compare(t, ok2, false)
+}
+
+func TestOptionOfUnwrapOr(t *testing.T) {
+	compare(t, SomeOf(42).UnwrapOr(21), 42)
+	compare(t, NoneOf[int]().UnwrapOr(21), 21)
+}
+
+func TestOptionOfUntyped(t *testing.T) {
+	compare(t, SomeOf(42).Untyped(), Some(42))
+	compare(t, NoneOf[int]().Untyped(), None())
+
+	o1, ok1 := OptionFrom[int](Some(42))
+	o2, ok2 := OptionFrom[int](None())
+	o3, ok3 := OptionFrom[int](Some("42"))
+	compare(t, o1, SomeOf(42))
+	compare(t, ok1, true)
+	compare(t, o2, NoneOf[int]())
+	compare(t, ok2, true)
+	compare(t, o3, NoneOf[int]())
+	compare(t, ok3, false)
+}
+
+func TestOptionOfMap(t *testing.T) {
+	compare(t, MapOption(SomeOf(42), strconv.Itoa), SomeOf("42"))
+	compare(t, MapOption(NoneOf[int](), strconv.Itoa), NoneOf[string]())
+}
+
+func TestOptionOfFlatMap(t *testing.T) {
+	half := func(i int) OptionOf[int] {
+		if i%2 != 0 {
+			return NoneOf[int]()
+		}
+		return SomeOf(i / 2)
+	}
+
+	compare(t, FlatMapOption(SomeOf(42), half), SomeOf(21))
+	compare(t, FlatMapOption(SomeOf(21), half), NoneOf[int]())
+	compare(t, FlatMapOption(NoneOf[int](), half), NoneOf[int]())
+}
+
+func TestOptionOfMatch(t *testing.T) {
+	onSome := func(i int) string { return strconv.Itoa(i * 2) }
+	onNone := func() string { return "none" }
+
+	compare(t, MatchOption(SomeOf(21), onSome, onNone), "42")
+	compare(t, MatchOption(NoneOf[int](), onSome, onNone), "none")
+}
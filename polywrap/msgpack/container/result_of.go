@@ -0,0 +1,91 @@
+package container
+
+// ResultOf is the typed counterpart of Result.
+type ResultOf[T any] struct {
+	value T
+	err   error
+}
+
+func AsResultOf[T any](value T, err error) ResultOf[T] {
+	if err != nil {
+		return ErrOf[T](err)
+	}
+	return OkOf(value)
+}
+
+func OkOf[T any](value T) ResultOf[T] {
+	return ResultOf[T]{
+		value: value,
+		err:   nil,
+	}
+}
+
+func ErrOf[T any](err error) ResultOf[T] {
+	return ResultOf[T]{
+		err: err,
+	}
+}
+
+func (r ResultOf[T]) IsOk() bool {
+	return r.err == nil
+}
+
+func (r ResultOf[T]) IsError() bool {
+	return r.err != nil
+}
+
+func (r ResultOf[T]) Error() error {
+	return r.err
+}
+
+func (r ResultOf[T]) Get() (T, error) {
+	return r.value, r.err
+}
+
+func (r ResultOf[T]) MustGet() T {
+	if r.IsError() {
+		panic(r.err)
+	}
+	return r.value
+}
+
+func (r ResultOf[T]) UnwrapOr(fallback T) T {
+	if r.IsError() {
+		return fallback
+	}
+	return r.value
+}
+
+// Untyped converts r to a Result.
+func (r ResultOf[T]) Untyped() Result {
+	if r.IsError() {
+		return Err(r.err)
+	}
+	return Ok(r.value)
+}
+
+// MapResult returns fn applied to the value of r, or the error of r.
+func MapResult[T, U any](r ResultOf[T], fn func(T) U) ResultOf[U] {
+	if r.IsError() {
+		return ErrOf[U](r.err)
+	}
+	return OkOf(fn(r.value))
+}
+
+// FlatMapResult returns the result returned by fn for the value of r, or the
+// error of r.
+func FlatMapResult[T, U any](r ResultOf[T], fn func(T) ResultOf[U]) ResultOf[U] {
+	if r.IsError() {
+		return ErrOf[U](r.err)
+	}
+	return fn(r.value)
+}
+
+// MatchResult returns onOk applied to the value of r, or onError applied to
+// its error.
+func MatchResult[T, U any](r ResultOf[T], onOk func(T) U, onError func(error) U) U {
+	if r.IsError() {
+		return onError(r.err)
+	}
+	return onOk(r.value)
+}
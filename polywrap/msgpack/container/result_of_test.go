@@ -0,0 +1,64 @@
+package container
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestResultOfOk(t *testing.T) {
+	compare(t, OkOf(42), ResultOf[int]{value: 42, err: nil})
+	compare(t, OkOf(42).IsOk(), true)
+	compare(t, OkOf(42).IsError(), false)
+}
+
+func TestResultOfErr(t *testing.T) {
+	compare(t, ErrOf[int](err), ResultOf[int]{value: 0, err: err})
+	compare(t, ErrOf[int](err).IsOk(), false)
+	compare(t, ErrOf[int](err).IsError(), true)
+	compare(t, ErrOf[int](err).Error(), err)
+}
+
+func TestResultOfAsResult(t *testing.T) {
+	compare(t, AsResultOf(42, nil), OkOf(42))
+	compare(t, AsResultOf(42, err), ErrOf[int](err))
+}
+
+func TestResultOfGet(t *testing.T) {
+	v1, err1 := OkOf(42).Get()
+	v2, err2 := ErrOf[int](err).Get()
+	compare(t, v1, 42)
+	compare(t, err1, nil)
+	compare(t, v2, 0)
+	compare(t, err2, err)
+}
+
+func TestResultOfUnwrapOr(t *testing.T) {
+	compare(t, OkOf(42).UnwrapOr(21), 42)
+	compare(t, ErrOf[int](err).UnwrapOr(21), 21)
+}
+
+func TestResultOfUntyped(t *testing.T) {
+	compare(t, OkOf(42).Untyped(), Ok(42))
+	compare(t, ErrOf[int](err).Untyped(), Err(err))
+}
+
+func TestResultOfMap(t *testing.T) {
+	compare(t, MapResult(OkOf(42), strconv.Itoa), OkOf("42"))
+	compare(t, MapResult(ErrOf[int](err), strconv.Itoa), ErrOf[string](err))
+}
+
+func TestResultOfFlatMap(t *testing.T) {
+	parse := func(s string) ResultOf[int] { return AsResultOf(strconv.Atoi(s)) }
+
+	compare(t, FlatMapResult(OkOf("42"), parse), OkOf(42))
+	compare(t, FlatMapResult(OkOf("x"), parse).IsError(), true)
+	compare(t, FlatMapResult(ErrOf[string](err), parse), ErrOf[int](err))
+}
+
+func TestResultOfMatch(t *testing.T) {
+	onOk := func(i int) string { return strconv.Itoa(i * 2) }
+	onError := func(e error) string { return e.Error() }
+
+	compare(t, MatchResult(OkOf(21), onOk, onError), "42")
+	compare(t, MatchResult(ErrOf[int](err), onOk, onError), err.Error())
+}
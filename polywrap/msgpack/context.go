@@ -1,20 +1,38 @@
 package msgpack
 
+import (
+	"strings"
+)
+
+// Node is an entry of the Context stack.
 type Node struct {
-	nodeItem string
-	nodeType string
-	nodeInfo string
+	// Item is the property name or array index being processed.
+	Item string
+	Type string
+	Info string
+	// Offset is the position in the buffer when the node was pushed, or -1 if
+	// the context is not used by an encoder or decoder.
+	Offset int
 }
 
 type Context struct {
 	description string
 	nodes       []Node
+	// offset returns the position of the encoder or decoder using the context
+	offset func() int
 }
 
 func NewContext(description string) *Context {
 	return &Context{description: description}
 }
 
+// Reset empties the stack and sets a new description, keeping the allocated
+// stack for reuse.
+func (c *Context) Reset(description string) {
+	c.description = description
+	c.nodes = c.nodes[:0]
+}
+
 func (c *Context) IsEmpty() bool {
 	return len(c.nodes) == 0
 }
@@ -23,11 +41,25 @@ func (c *Context) Length() int32 {
 	return int32(len(c.nodes))
 }
 
+// bind makes offset the source of node offsets, unless the context is already
+// bound. Helpers such as the sizer run by WriteExtGenericMap share the
+// context of their encoder and must not take it over.
+func (c *Context) bind(offset func() int) {
+	if c.offset == nil {
+		c.offset = offset
+	}
+}
+
 func (c *Context) Push(nodeItem, nodeType, nodeInfo string) {
+	offset := -1
+	if c.offset != nil {
+		offset = c.offset()
+	}
 	c.nodes = append(c.nodes, Node{
-		nodeItem: nodeItem,
-		nodeType: nodeType,
-		nodeInfo: nodeInfo,
+		Item:   nodeItem,
+		Type:   nodeType,
+		Info:   nodeInfo,
+		Offset: offset,
 	})
 }
 
@@ -40,10 +72,44 @@ func (c *Context) Pop() string {
 	c.nodes = a
 
 	nodeInfo := ""
-	if node.nodeInfo != "" {
-		nodeInfo = " >> " + node.nodeInfo
+	if node.Info != "" {
+		nodeInfo = " >> " + node.Info
 	}
-	return node.nodeItem + ": " + node.nodeType + nodeInfo
+	return node.Item + ": " + node.Type + nodeInfo
+}
+
+// Nodes returns a copy of the stack, outermost node first.
+func (c *Context) Nodes() []Node {
+	nodes := make([]Node, len(c.nodes))
+	copy(nodes, c.nodes)
+	return nodes
+}
+
+// Path renders the stack as a JSON pointer, e.g. "/args/items/3/value".
+// The "searching for property type" and "type found, reading property" steps
+// of a property appear once.
+func (c *Context) Path() string {
+	var path strings.Builder
+	for i := range c.nodes {
+		if i > 0 && c.nodes[i].Item == c.nodes[i-1].Item && c.nodes[i-1].Info == searchingInfo {
+			continue
+		}
+		path.WriteString("/")
+		path.WriteString(pathEscaper.Replace(c.nodes[i].Item))
+	}
+	return path.String()
+}
+
+// searchingInfo is the Info of the node pushed while a property is looked
+// up, before the node that reads it.
+const searchingInfo = "searching for property type"
+
+var pathEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func (c *Context) clone() *Context {
+	nodes := make([]Node, len(c.nodes))
+	copy(nodes, c.nodes)
+	return &Context{description: c.description, nodes: nodes}
 }
 
 func (c *Context) toString() string {
@@ -69,13 +135,13 @@ func (c *Context) printWithTabs(tabs, size int32) string {
 	for i := len(c.nodes) - 1; i >= 0; i-- {
 		node := c.nodes[i]
 		nodeInfo := ""
-		if node.nodeInfo != "" {
-			nodeInfo = " >> " + node.nodeInfo
+		if node.Info != "" {
+			nodeInfo = " >> " + node.Info
 		}
 
 		result += rpad("\n", " ", size*tabs+1)
 		tabs++
-		result += "at " + node.nodeItem + ": " + node.nodeType + nodeInfo
+		result += "at " + node.Item + ": " + node.Type + nodeInfo
 	}
 
 	return result
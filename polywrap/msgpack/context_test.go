@@ -83,3 +83,93 @@ func TestPrints(t *testing.T) {
 		t.Errorf("PrintWithContext() is incorrect: \ngot \n%s \nwant \n%s", actual, expected)
 	}
 }
+
+func TestContextPath(t *testing.T) {
+	c := NewContext("Deserializing MyObject")
+	if c.Path() != "" {
+		t.Errorf("Bad empty path, got: %q", c.Path())
+	}
+
+	c.Push("args", "Args", "searching for property type")
+	c.Push("args", "Args", "type found, reading property")
+	c.Push("items", "[]Item", "type found, reading property")
+	c.Push("3", "Item", "reading array item")
+	c.Push("a/b~c", "string", "type found, reading property")
+
+	expected := "/args/items/3/a~1b~0c"
+	if actual := c.Path(); actual != expected {
+		t.Errorf("Bad path, got: %q, want: %q", actual, expected)
+	}
+
+	nodes := c.Nodes()
+	if len(nodes) != 5 || nodes[2].Item != "items" || nodes[2].Type != "[]Item" || nodes[2].Offset != -1 {
+		t.Errorf("Bad nodes, got: %+v", nodes)
+	}
+	nodes[0].Item = "changed"
+	if c.Nodes()[0].Item != "args" {
+		t.Errorf("Nodes must return a copy")
+	}
+
+	c.Reset("Deserializing [][]int32")
+	c.Push("0", "[]int32", "reading array item")
+	c.Push("0", "int32", "reading array item")
+	c.Push("3", "[]int32", "reading array item")
+	c.Push("3", "int32", "reading array item")
+	if actual := c.Path(); actual != "/0/0/3/3" {
+		t.Errorf("Bad nested array path, got: %q, want: %q", actual, "/0/0/3/3")
+	}
+}
+
+func TestContextOffsets(t *testing.T) {
+	context := NewContext("Deserializing")
+	reader := NewReadDecoder(context, []byte{0x01, 0xa1, 'a', 0x02})
+	reader.ReadU8()
+	context.Push("first", "string", "")
+	reader.ReadString()
+	context.Push("second", "uint8", "")
+
+	nodes := context.Nodes()
+	if nodes[0].Offset != 1 || nodes[1].Offset != 3 {
+		t.Errorf("Bad read offsets, got: %+v", nodes)
+	}
+
+	context = NewContext("Serializing")
+	encoder := NewWriteEncoder(context)
+	encoder.WriteString("abc")
+	context.Push("second", "uint8", "")
+	if offset := context.Nodes()[0].Offset; offset != 4 {
+		t.Errorf("Bad write offset, got: %v", offset)
+	}
+}
+
+func TestUnmarshalErrorPath(t *testing.T) {
+	type item struct {
+		Label string
+	}
+	type args struct {
+		Items []item
+	}
+
+	data, err := Marshal(map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"label": "first"},
+			map[string]interface{}{"label": true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var v args
+	decodeErr, ok := Unmarshal(data, &v).(*DecodeError)
+	if !ok {
+		t.Fatalf("Expected *DecodeError")
+	}
+	if path := decodeErr.Context.Path(); path != "/items/1/label" {
+		t.Errorf("Bad path, got: %q", path)
+	}
+	nodes := decodeErr.Context.Nodes()
+	if offset := nodes[len(nodes)-1].Offset; data[offset] != 0xc3 {
+		t.Errorf("Bad offset, got: %v (%x)", offset, data[offset])
+	}
+}
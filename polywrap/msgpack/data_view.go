@@ -1,42 +1,170 @@
 package msgpack
 
 import (
-	"bytes"
 	"encoding/binary"
+	"io"
+	"math"
 
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
 )
 
+// streamChunkSize is the size of the reads from the source of a streaming
+// DataView and the amount of buffered output that triggers a flush to its
+// sink.
+const streamChunkSize = 32 * 1024
+
+// DataView is a big-endian cursor over a byte slice. Reads past the end of
+// the data panic, ReadDecoder checks the remaining length before reading.
 type DataView struct {
-	buf     *bytes.Buffer
+	buf     []byte
+	pos     int
 	context *Context
+
+	// base is the number of bytes dropped from the front of buf, src and dst
+	// are set for streaming views only
+	base int
+	src  io.Reader
+	dst  io.Writer
+	err  error
 }
 
 func NewDataView(context *Context) *DataView {
+	return &DataView{context: context}
+}
+
+// NewDataViewWithSize returns a writable DataView whose buffer is allocated
+// once with the given capacity.
+func NewDataViewWithSize(context *Context, size int32) *DataView {
 	return &DataView{
-		buf:     new(bytes.Buffer),
+		buf:     make([]byte, 0, size),
 		context: context,
 	}
 }
 
+// NewDataViewWithBuf returns a DataView reading data. The data is not copied.
 func NewDataViewWithBuf(context *Context, data []byte) *DataView {
 	return &DataView{
-		buf:     bytes.NewBuffer(data),
+		buf:     data,
 		context: context,
 	}
 }
 
-func (dw *DataView) WriteFormat(value format.Format) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteUint8 error " + err.Error())
+// NewDataViewWithReader returns a DataView that reads its data from r as it
+// is consumed.
+func NewDataViewWithReader(context *Context, r io.Reader) *DataView {
+	return &DataView{
+		context: context,
+		src:     r,
+	}
+}
+
+// NewDataViewWithWriter returns a DataView that writes its data to w once
+// Flush is called or enough output is buffered.
+func NewDataViewWithWriter(context *Context, w io.Writer) *DataView {
+	return &DataView{
+		context: context,
+		dst:     w,
+	}
+}
+
+// Reset makes the view read data from the start, or write after data when
+// data is a truncated buffer. Streaming views keep their source and sink.
+func (dw *DataView) Reset(data []byte) {
+	dw.buf = data
+	dw.pos = 0
+	dw.base = 0
+	dw.err = nil
+}
+
+// Fill buffers up to n bytes from the source of a streaming view and returns
+// the number of bytes that can be read without blocking, which is less than
+// n only at the end of the input or on a read error. Filling may move the
+// buffered data, so slices returned by ReadBytesView are only valid until the
+// next read from a streaming view.
+func (dw *DataView) Fill(n uint32) int {
+	for dw.src != nil && uint64(dw.Remaining()) < uint64(n) {
+		if dw.pos > 0 {
+			dw.base += dw.pos
+			dw.buf = dw.buf[:copy(dw.buf, dw.buf[dw.pos:])]
+			dw.pos = 0
+		}
+		if cap(dw.buf)-len(dw.buf) < streamChunkSize {
+			grown := make([]byte, len(dw.buf), 2*cap(dw.buf)+streamChunkSize)
+			copy(grown, dw.buf)
+			dw.buf = grown
+		}
+		read, err := dw.src.Read(dw.buf[len(dw.buf):cap(dw.buf)])
+		dw.buf = dw.buf[:len(dw.buf)+read]
+		if err != nil {
+			if err != io.EOF {
+				dw.err = err
+			}
+			dw.src = nil
+		}
 	}
+	return dw.Remaining()
+}
+
+// Err returns the error returned by the source or the sink of a streaming
+// view, if any.
+func (dw *DataView) Err() error {
+	return dw.err
+}
+
+// Flush writes the buffered output to the sink of a streaming view.
+func (dw *DataView) Flush() error {
+	if dw.dst == nil || dw.err != nil {
+		return dw.err
+	}
+	if _, err := dw.dst.Write(dw.buf); err != nil {
+		dw.err = err
+	}
+	dw.base += len(dw.buf)
+	dw.buf = dw.buf[:0]
+	return dw.err
+}
+
+func (dw *DataView) flushIfFull() {
+	if dw.dst != nil && len(dw.buf) >= streamChunkSize {
+		dw.Flush()
+	}
+}
+
+// Bytes returns the data written so far, or for a streaming view the data
+// not flushed yet.
+func (dw *DataView) Bytes() []byte {
+	return dw.buf
+}
+
+// Offset returns the position of the next byte to be read.
+func (dw *DataView) Offset() int {
+	return dw.base + dw.pos
+}
+
+// Written returns the number of bytes written, including flushed ones.
+func (dw *DataView) Written() int {
+	return dw.base + len(dw.buf)
+}
+
+// Remaining returns the number of unread bytes. For a streaming view it only
+// counts the buffered ones, see Fill.
+func (dw *DataView) Remaining() int {
+	return len(dw.buf) - dw.pos
+}
+
+// next consumes n bytes and returns them without copying.
+func (dw *DataView) next(n int) []byte {
+	b := dw.buf[dw.pos : dw.pos+n : dw.pos+n]
+	dw.pos += n
+	return b
+}
+
+func (dw *DataView) WriteFormat(value format.Format) {
+	dw.WriteUint8(uint8(value))
 }
 
 func (dw *DataView) PeekFormat() format.Format {
-	f := dw.ReadUint8()
-	dw.buf.UnreadByte()
-	return format.Format(f)
+	return format.Format(dw.buf[dw.pos])
 }
 
 func (dw *DataView) ReadFormat() format.Format {
@@ -44,182 +172,128 @@ func (dw *DataView) ReadFormat() format.Format {
 }
 
 func (dw *DataView) WriteUint8(value uint8) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteUint8 error " + err.Error())
-	}
+	dw.flushIfFull()
+	dw.buf = append(dw.buf, value)
 }
 
 func (dw *DataView) ReadUint8() uint8 {
-	var result uint8
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadUint8 error " + err.Error())
-	}
-	return result
+	value := dw.buf[dw.pos]
+	dw.pos++
+	return value
 }
 
 func (dw *DataView) WriteUint16(value uint16) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteUint16 error " + err.Error())
-	}
+	dw.buf = append(dw.buf, byte(value>>8), byte(value))
 }
 
 func (dw *DataView) ReadUint16() uint16 {
-	var result uint16
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadUint16 error " + err.Error())
-	}
-	return result
+	return binary.BigEndian.Uint16(dw.next(2))
 }
 
 func (dw *DataView) WriteUint32(value uint32) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteUint32 error " + err.Error())
-	}
+	dw.buf = append(dw.buf, byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
 }
 
 func (dw *DataView) ReadUint32() uint32 {
-	var result uint32
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadUint8 error " + err.Error())
-	}
-	return result
+	return binary.BigEndian.Uint32(dw.next(4))
 }
 
 func (dw *DataView) WriteUint64(value uint64) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteUint64 error " + err.Error())
-	}
+	dw.buf = append(dw.buf, byte(value>>56), byte(value>>48), byte(value>>40), byte(value>>32),
+		byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
 }
 
 func (dw *DataView) ReadUint64() uint64 {
-	var result uint64
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadUint8 error " + err.Error())
-	}
-	return result
+	return binary.BigEndian.Uint64(dw.next(8))
 }
 
 func (dw *DataView) WriteInt8(value int8) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteInt8 error " + err.Error())
-	}
+	dw.WriteUint8(uint8(value))
 }
 
 func (dw *DataView) ReadInt8() int8 {
-	var result int8
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadInt8 error " + err.Error())
-	}
-	return result
+	return int8(dw.ReadUint8())
 }
 
 func (dw *DataView) WriteInt16(value int16) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteInt16 error " + err.Error())
-	}
+	dw.WriteUint16(uint16(value))
 }
 
 func (dw *DataView) ReadInt16() int16 {
-	var result int16
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadInt16 error " + err.Error())
-	}
-	return result
+	return int16(dw.ReadUint16())
 }
 
 func (dw *DataView) WriteInt32(value int32) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteInt32 error " + err.Error())
-	}
+	dw.WriteUint32(uint32(value))
 }
 
 func (dw *DataView) ReadInt32() int32 {
-	var result int32
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadInt8 error " + err.Error())
-	}
-	return result
+	return int32(dw.ReadUint32())
 }
 
 func (dw *DataView) WriteInt64(value int64) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteInt64 error " + err.Error())
-	}
+	dw.WriteUint64(uint64(value))
 }
 
 func (dw *DataView) ReadInt64() int64 {
-	var result int64
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadInt8 error " + err.Error())
-	}
-	return result
+	return int64(dw.ReadUint64())
 }
 
 func (dw *DataView) WriteFloat32(value float32) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteFloat32 error " + err.Error())
-	}
+	dw.WriteUint32(math.Float32bits(value))
 }
 
 func (dw *DataView) ReadFloat32() float32 {
-	var result float32
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadFloat32 error " + err.Error())
-	}
-	return result
+	return math.Float32frombits(dw.ReadUint32())
 }
 
 func (dw *DataView) WriteFloat64(value float64) {
-	err := binary.Write(dw.buf, binary.BigEndian, value)
-	if err != nil {
-		panic("WriteFloat64 error " + err.Error())
-	}
+	dw.WriteUint64(math.Float64bits(value))
 }
 
 func (dw *DataView) ReadFloat64() float64 {
-	var result float64
-	err := binary.Read(dw.buf, binary.BigEndian, &result)
-	if err != nil {
-		panic("ReadFloat64 error " + err.Error())
-	}
-	return result
+	return math.Float64frombits(dw.ReadUint64())
+}
+
+// Skip discards the next n bytes.
+func (dw *DataView) Skip(n uint32) {
+	dw.pos += int(n)
 }
 
 func (dw *DataView) WriteString(value string) {
-	dw.buf.WriteString(value)
+	if dw.dst != nil && len(dw.buf)+len(value) > streamChunkSize {
+		dw.Flush()
+	}
+	dw.buf = append(dw.buf, value...)
 }
 
+// ReadString returns the unread data as a string without consuming it.
 func (dw *DataView) ReadString() string {
-	return string(dw.buf.Bytes())
+	return string(dw.buf[dw.pos:])
 }
 
 func (dw *DataView) WriteBytes(value []byte) {
-	dw.buf.Write(value)
+	if dw.dst != nil && len(dw.buf)+len(value) > streamChunkSize {
+		// large payloads bypass the buffer
+		if dw.Flush() == nil && len(value) >= streamChunkSize {
+			if _, err := dw.dst.Write(value); err != nil {
+				dw.err = err
+			}
+			dw.base += len(value)
+			return
+		}
+	}
+	dw.buf = append(dw.buf, value...)
 }
 
+// ReadBytes returns a copy of the next ln bytes.
 func (dw *DataView) ReadBytes(ln uint32) []byte {
 	tmp := make([]byte, ln)
-	err := binary.Read(dw.buf, binary.BigEndian, tmp)
-	if err != nil {
-		panic("ReadFloat64 error " + err.Error())
-	}
+	copy(tmp, dw.next(int(ln)))
 	return tmp
 }
+
+// ReadBytesView returns the next ln bytes without copying them.
+func (dw *DataView) ReadBytesView(ln uint32) []byte {
+	return dw.next(int(ln))
+}
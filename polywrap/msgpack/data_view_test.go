@@ -0,0 +1,231 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+)
+
+// legacyDataView is the bytes.Buffer and encoding/binary based DataView the
+// current implementation replaced, kept to compare output and performance.
+type legacyDataView struct {
+	buf *bytes.Buffer
+}
+
+func (dw *legacyDataView) WriteFormat(value format.Format) {
+	binary.Write(dw.buf, binary.BigEndian, value)
+}
+
+func (dw *legacyDataView) PeekFormat() format.Format {
+	var f uint8
+	binary.Read(dw.buf, binary.BigEndian, &f)
+	dw.buf.UnreadByte()
+	return format.Format(f)
+}
+
+func (dw *legacyDataView) ReadFormat() format.Format {
+	var f uint8
+	binary.Read(dw.buf, binary.BigEndian, &f)
+	return format.Format(f)
+}
+
+func (dw *legacyDataView) WriteUint32(value uint32) {
+	binary.Write(dw.buf, binary.BigEndian, value)
+}
+
+func (dw *legacyDataView) ReadUint32() uint32 {
+	var result uint32
+	binary.Read(dw.buf, binary.BigEndian, &result)
+	return result
+}
+
+func (dw *legacyDataView) WriteFloat64(value float64) {
+	binary.Write(dw.buf, binary.BigEndian, value)
+}
+
+func (dw *legacyDataView) ReadFloat64() float64 {
+	var result float64
+	binary.Read(dw.buf, binary.BigEndian, &result)
+	return result
+}
+
+func (dw *legacyDataView) WriteString(value string) {
+	dw.buf.WriteString(value)
+}
+
+func (dw *legacyDataView) ReadBytes(ln uint32) []byte {
+	tmp := make([]byte, ln)
+	binary.Read(dw.buf, binary.BigEndian, tmp)
+	return tmp
+}
+
+const dataViewSample = "The quick brown fox jumps over the lazy dog"
+
+func writeLegacySample(dw *legacyDataView) {
+	dw.WriteFormat(format.UINT32)
+	dw.WriteUint32(0xdeadbeef)
+	dw.WriteFormat(format.FLOAT64)
+	dw.WriteFloat64(3.14159)
+	dw.WriteFormat(format.STR8)
+	dw.WriteString(dataViewSample)
+}
+
+func writeSample(dw *DataView) {
+	dw.WriteFormat(format.UINT32)
+	dw.WriteUint32(0xdeadbeef)
+	dw.WriteFormat(format.FLOAT64)
+	dw.WriteFloat64(3.14159)
+	dw.WriteFormat(format.STR8)
+	dw.WriteString(dataViewSample)
+}
+
+func TestDataViewMatchesLegacy(t *testing.T) {
+	legacy := &legacyDataView{buf: new(bytes.Buffer)}
+	writeLegacySample(legacy)
+	current := NewDataView(NewContext(""))
+	writeSample(current)
+
+	if !bytes.Equal(current.Bytes(), legacy.buf.Bytes()) {
+		t.Fatalf("Bad value, got: %x, want: %x", current.Bytes(), legacy.buf.Bytes())
+	}
+
+	reader := NewDataViewWithBuf(NewContext(""), current.Bytes())
+	if f := reader.PeekFormat(); f != format.UINT32 || reader.Offset() != 0 {
+		t.Errorf("Bad peek, got: %v at %v", f, reader.Offset())
+	}
+	reader.ReadFormat()
+	if v := reader.ReadUint32(); v != 0xdeadbeef {
+		t.Errorf("Bad uint32, got: %x", v)
+	}
+	reader.ReadFormat()
+	if v := reader.ReadFloat64(); v != 3.14159 {
+		t.Errorf("Bad float64, got: %v", v)
+	}
+	reader.ReadFormat()
+	view := reader.ReadBytesView(uint32(len(dataViewSample)))
+	if string(view) != dataViewSample || reader.Remaining() != 0 {
+		t.Errorf("Bad string, got: %q", view)
+	}
+	if cap(view) != len(view) {
+		t.Errorf("View capacity must be limited, got: %v", cap(view))
+	}
+}
+
+func TestReadViewsAlias(t *testing.T) {
+	data := []byte{0xa3, 'a', 'b', 'c', 0xc4, 0x02, 0x01, 0x02}
+	reader := NewReadDecoder(NewContext(""), data)
+
+	str := reader.ReadStringView()
+	bin := reader.ReadBytesView()
+	if string(str) != "abc" || !bytes.Equal(bin, []byte{0x01, 0x02}) {
+		t.Fatalf("Bad views, got: %q, %v", str, bin)
+	}
+	if &str[0] != &data[1] || &bin[0] != &data[6] {
+		t.Errorf("Views do not alias the input")
+	}
+}
+
+func BenchmarkDataViewWrite(b *testing.B) {
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dw := &legacyDataView{buf: new(bytes.Buffer)}
+			for j := 0; j < 100; j++ {
+				writeLegacySample(dw)
+			}
+		}
+	})
+	b.Run("current", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dw := NewDataView(nil)
+			for j := 0; j < 100; j++ {
+				writeSample(dw)
+			}
+		}
+	})
+}
+
+func BenchmarkDataViewRead(b *testing.B) {
+	dw := NewDataView(nil)
+	for j := 0; j < 100; j++ {
+		writeSample(dw)
+	}
+	data := dw.Bytes()
+	ln := uint32(len(dataViewSample))
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dw := &legacyDataView{buf: bytes.NewBuffer(data)}
+			for j := 0; j < 100; j++ {
+				dw.PeekFormat()
+				dw.ReadFormat()
+				dw.ReadUint32()
+				dw.ReadFormat()
+				dw.ReadFloat64()
+				dw.ReadFormat()
+				dw.ReadBytes(ln)
+			}
+		}
+	})
+	b.Run("current", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dw := NewDataViewWithBuf(nil, data)
+			for j := 0; j < 100; j++ {
+				dw.PeekFormat()
+				dw.ReadFormat()
+				dw.ReadUint32()
+				dw.ReadFormat()
+				dw.ReadFloat64()
+				dw.ReadFormat()
+				dw.ReadBytes(ln)
+			}
+		}
+	})
+	b.Run("view", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dw := NewDataViewWithBuf(nil, data)
+			for j := 0; j < 100; j++ {
+				dw.PeekFormat()
+				dw.ReadFormat()
+				dw.ReadUint32()
+				dw.ReadFormat()
+				dw.ReadFloat64()
+				dw.ReadFormat()
+				dw.ReadBytesView(ln)
+			}
+		}
+	})
+}
+
+func BenchmarkReadDecoderString(b *testing.B) {
+	encoder := NewWriteEncoder(NewContext(""))
+	for j := 0; j < 100; j++ {
+		encoder.WriteString(dataViewSample)
+	}
+	data := encoder.Buffer()
+
+	b.Run("copy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reader := NewReadDecoder(NewContext(""), data)
+			for j := 0; j < 100; j++ {
+				reader.ReadString()
+			}
+		}
+	})
+	b.Run("view", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reader := NewReadDecoder(NewContext(""), data)
+			for j := 0; j < 100; j++ {
+				reader.ReadStringView()
+			}
+		}
+	})
+}
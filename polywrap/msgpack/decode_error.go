@@ -0,0 +1,29 @@
+package msgpack
+
+import "github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+
+// DecodeError is returned by a ReadDecoder when its input can not be decoded.
+type DecodeError struct {
+	// Message describes the failure.
+	Message string
+	// Context is a snapshot of the decoder context at the moment of failure.
+	Context *Context
+	// Expected is the format the decoder was looking for. For families of
+	// formats (strings, ints, maps, ...) it is the widest member of the family.
+	// It is format.ERROR when the input ended early.
+	Expected format.Format
+	// Found is the format that was actually read, or format.ERROR when the
+	// input ended early.
+	Found format.Format
+	// Offset is the position in the input of the item that failed to decode.
+	Offset int
+	// Dump lists the items around Offset when ReadOptions.DumpWindow is set.
+	Dump string
+}
+
+func (e *DecodeError) Error() string {
+	if e.Dump != "" {
+		return e.Context.PrintWithContext(e.Message) + "\n" + e.Dump
+	}
+	return e.Context.PrintWithContext(e.Message)
+}
@@ -0,0 +1,173 @@
+package msgpack
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+)
+
+// dumpValueLimit is the number of string characters and binary bytes Dump
+// prints before truncating a value.
+const dumpValueLimit = 32
+
+// Dump returns a listing of the msgpack items in data, one per line, with the
+// offset and the raw format byte in hex, the format name indented by nesting
+// depth, the length of strings, binaries, arrays, maps and extensions, and the
+// value of scalars:
+//
+//	000000  82  map len=2
+//	000001  a3    string len=3 "key"
+//	000005  cd    uint16 1000
+//
+// Long values are truncated. Malformed input ends the listing with the decode
+// error.
+func Dump(data []byte) string {
+	return dump(data, 0, len(data), -1)
+}
+
+// dump lists the items whose offset is in [from, to] and marks the one at
+// offset mark.
+func dump(data []byte, from, to, mark int) string {
+	d := &dumper{
+		reader: NewReadDecoderWithOptions(NewContext("Dumping msgpack"), data, ReadOptions{}),
+		from:   from,
+		to:     to,
+		mark:   mark,
+	}
+	for d.reader.err == nil && d.reader.view.Remaining() > 0 && d.reader.view.Offset() <= to {
+		d.item(0)
+	}
+	if err := d.reader.err; err != nil && err.Offset <= to {
+		d.out.WriteString("error at " + strconv.Itoa(err.Offset) + ": " + err.Message + "\n")
+	}
+	return d.out.String()
+}
+
+type dumper struct {
+	reader   *ReadDecoder
+	out      strings.Builder
+	from, to int
+	mark     int
+}
+
+func (d *dumper) line(offset int, f format.Format, depth int, length string, value string) {
+	if offset < d.from || offset > d.to {
+		return
+	}
+	text := strconv.FormatInt(int64(offset), 16)
+	d.out.WriteString(strings.Repeat("0", 6-len(text)) + text + "  ")
+	d.out.WriteString(hex.EncodeToString([]byte{byte(f)}) + "  ")
+	d.out.WriteString(strings.Repeat("  ", depth) + format.ToString(f))
+	if length != "" {
+		d.out.WriteString(" len=" + length)
+	}
+	if value != "" {
+		d.out.WriteString(" " + value)
+	}
+	if offset == d.mark {
+		d.out.WriteString("  <--")
+	}
+	d.out.WriteString("\n")
+}
+
+func (d *dumper) item(depth int) {
+	reader := d.reader
+	offset := reader.view.Offset()
+	f := reader.peekFormat()
+	if reader.err != nil || reader.view.Remaining() == 0 {
+		// reports the end of input
+		reader.readFormat()
+		return
+	}
+
+	switch {
+	case f == format.NIL:
+		reader.readFormat()
+		d.line(offset, f, depth, "", "nil")
+	case f == format.TRUE || f == format.FALSE:
+		d.line(offset, f, depth, "", strconv.FormatBool(reader.ReadBool()))
+	case isFixedInt(uint8(f)), f == format.UINT8, f == format.UINT16, f == format.UINT32, f == format.UINT64:
+		value := reader.ReadU64()
+		if reader.err == nil {
+			d.line(offset, f, depth, "", strconv.FormatUint(value, 10))
+		}
+	case isNegativeFixedInt(uint8(f)), f == format.INT8, f == format.INT16, f == format.INT32, f == format.INT64:
+		value := reader.ReadI64()
+		if reader.err == nil {
+			d.line(offset, f, depth, "", strconv.FormatInt(value, 10))
+		}
+	case f == format.FLOAT32:
+		value := reader.ReadF32()
+		if reader.err == nil {
+			d.line(offset, f, depth, "", strconv.FormatFloat(float64(value), 'g', -1, 32))
+		}
+	case f == format.FLOAT64:
+		value := reader.ReadF64()
+		if reader.err == nil {
+			d.line(offset, f, depth, "", strconv.FormatFloat(value, 'g', -1, 64))
+		}
+	case isFixedString(uint8(f)), f == format.STR8, f == format.STR16, f == format.STR32:
+		ln := reader.ReadStringLength()
+		value := reader.readBytesView(ln)
+		if reader.err == nil {
+			if len(value) > dumpValueLimit {
+				d.line(offset, f, depth, strconv.Itoa(int(ln)), strconv.Quote(string(value[:dumpValueLimit]))+"...")
+			} else {
+				d.line(offset, f, depth, strconv.Itoa(int(ln)), strconv.Quote(string(value)))
+			}
+		}
+	case f == format.BIN8, f == format.BIN16, f == format.BIN32:
+		ln := reader.ReadBytesLength()
+		value := reader.readBytesView(ln)
+		if reader.err == nil {
+			d.line(offset, f, depth, strconv.Itoa(int(ln)), dumpBytes(value))
+		}
+	case isFixedArray(uint8(f)), f == format.ARRAY16, f == format.ARRAY32:
+		size := reader.ReadArrayLength()
+		d.line(offset, f, depth, strconv.FormatUint(uint64(size), 10), "")
+		if !reader.enter() {
+			return
+		}
+		for i := uint32(0); i < size && reader.err == nil; i++ {
+			d.item(depth + 1)
+		}
+		reader.leave()
+	case isFixedMap(uint8(f)), f == format.MAP16, f == format.MAP32:
+		size := reader.ReadMapLength()
+		d.line(offset, f, depth, strconv.FormatUint(uint64(size), 10), "")
+		if !reader.enter() {
+			return
+		}
+		for i := uint64(0); i < 2*uint64(size) && reader.err == nil; i++ {
+			d.item(depth + 1)
+		}
+		reader.leave()
+	case isExt(f):
+		ln := reader.readExtLength()
+		typ := reader.readInt8()
+		if reader.err != nil {
+			return
+		}
+		if typ == ExtGenericMap {
+			d.line(offset, f, depth, strconv.FormatUint(uint64(ln), 10), "GenericMap")
+			d.item(depth + 1)
+			return
+		}
+		value := reader.readBytesView(ln)
+		if reader.err == nil {
+			d.line(offset, f, depth, strconv.FormatUint(uint64(ln), 10), "type="+strconv.Itoa(int(typ))+" "+dumpBytes(value))
+		}
+	default:
+		reader.readFormat()
+		reader.unexpected("Unknown format "+format.ToString(f), format.ERROR)
+	}
+}
+
+func dumpBytes(value []byte) string {
+	if len(value) > dumpValueLimit {
+		return hex.EncodeToString(value[:dumpValueLimit]) + "..."
+	}
+	return hex.EncodeToString(value)
+}
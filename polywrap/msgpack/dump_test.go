@@ -0,0 +1,69 @@
+package msgpack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDump(t *testing.T) {
+	encoder := NewWriteEncoder(NewContext(""))
+	encoder.WriteMapLength(3)
+	encoder.WriteString("key")
+	encoder.WriteU16(1000)
+	encoder.WriteString("list")
+	encoder.WriteArrayLength(2)
+	encoder.WriteI8(-5)
+	encoder.WriteBytes([]byte{0xca, 0xfe})
+	encoder.WriteString("ext")
+	encoder.WriteExt(5, []byte{0x01})
+
+	expected := `000000  83  map len=3
+000001  a3    string len=3 "key"
+000005  cd    uint16 1000
+000008  a4    string len=4 "list"
+00000d  92    array len=2
+00000e  fb      negative fixint -5
+00000f  c4      BIN8 len=2 cafe
+000013  a3    string len=3 "ext"
+000017  d4    FIXEXT1 len=1 type=5 01
+`
+	if actual := Dump(encoder.Buffer()); actual != expected {
+		t.Errorf("Bad dump, got:\n%s\nwant:\n%s", actual, expected)
+	}
+}
+
+func TestDumpTruncated(t *testing.T) {
+	actual := Dump([]byte{0x92, 0x01, 0xa5, 'a'})
+	expected := `000000  92  array len=2
+000001  01    positive fixint 1
+error at 3: Unexpected end of input: need 5 more byte(s), have 1
+`
+	if actual != expected {
+		t.Errorf("Bad dump, got:\n%s\nwant:\n%s", actual, expected)
+	}
+}
+
+func TestDecodeErrorDumpWindow(t *testing.T) {
+	data := []byte{0x93, 0x01, 0x02, 0x81, 0xa1, 'a', 0xc3}
+	reader := NewReadDecoderWithOptions(NewContext("Deserializing"), data, ReadOptions{DumpWindow: 2})
+	reader.ReadArrayLength()
+	reader.ReadI8()
+	reader.ReadI8()
+	reader.ReadString()
+
+	err, ok := reader.Err().(*DecodeError)
+	if !ok {
+		t.Fatalf("Expected *DecodeError, got: %v", reader.Err())
+	}
+	expected := `000001  01    positive fixint 1
+000002  02    positive fixint 2
+000003  81    map len=1  <--
+000004  a1      string len=1 "a"
+`
+	if err.Dump != expected {
+		t.Errorf("Bad dump, got:\n%s\nwant:\n%s", err.Dump, expected)
+	}
+	if !strings.HasSuffix(err.Error(), "\n"+expected) {
+		t.Errorf("Dump missing from message: %v", err.Error())
+	}
+}
@@ -0,0 +1,147 @@
+package msgpack
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+)
+
+// Extension type IDs reserved by the Polywrap toolchain.
+const (
+	// ExtGenericMap wraps a regular map to encode the schema type Map<K, V>.
+	ExtGenericMap int8 = 1
+)
+
+// ExtEncoder returns the extension payload for value.
+type ExtEncoder func(value interface{}) ([]byte, error)
+
+// ExtDecoder builds a value from an extension payload.
+type ExtDecoder func(data []byte) (interface{}, error)
+
+type extEntry struct {
+	typ     int8
+	goType  reflect.Type
+	encoder ExtEncoder
+	decoder ExtDecoder
+}
+
+// ExtRegistry maps msgpack extension type IDs to Go types and the functions
+// that convert between them and the extension payload.
+type ExtRegistry struct {
+	mu       sync.RWMutex
+	byType   map[int8]*extEntry
+	byGoType map[reflect.Type]*extEntry
+}
+
+// DefaultExtRegistry is used by RegisterExt, Marshal and Unmarshal.
+var DefaultExtRegistry = NewExtRegistry()
+
+func NewExtRegistry() *ExtRegistry {
+	return &ExtRegistry{
+		byType:   make(map[int8]*extEntry),
+		byGoType: make(map[reflect.Type]*extEntry),
+	}
+}
+
+// RegisterExt registers an extension type in DefaultExtRegistry.
+func RegisterExt(typ int8, value interface{}, encoder ExtEncoder, decoder ExtDecoder) error {
+	return DefaultExtRegistry.Register(typ, value, encoder, decoder)
+}
+
+// Register associates the extension type ID typ with the Go type of value.
+// Negative IDs are reserved by the msgpack specification and ExtGenericMap by
+// Polywrap. Each ID and each Go type can be registered only once.
+func (r *ExtRegistry) Register(typ int8, value interface{}, encoder ExtEncoder, decoder ExtDecoder) error {
+	if typ < 0 || typ == ExtGenericMap {
+		return errors.New("Extension type " + strconv.Itoa(int(typ)) + " is reserved")
+	}
+	goType := reflect.TypeOf(value)
+	if goType == nil {
+		return errors.New("Extension value must not be nil")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.byType[typ]; ok {
+		return errors.New("Extension type " + strconv.Itoa(int(typ)) + " is already registered")
+	}
+	if _, ok := r.byGoType[goType]; ok {
+		return errors.New("Go type '" + goType.String() + "' is already registered as an extension")
+	}
+
+	entry := &extEntry{typ: typ, goType: goType, encoder: encoder, decoder: decoder}
+	r.byType[typ] = entry
+	r.byGoType[goType] = entry
+	return nil
+}
+
+func (r *ExtRegistry) lookupGoType(goType reflect.Type) *extEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byGoType[goType]
+}
+
+func (r *ExtRegistry) lookupType(typ int8) *extEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byType[typ]
+}
+
+// Write encodes value as the extension registered for its Go type.
+func (r *ExtRegistry) Write(writer Write, value interface{}) error {
+	entry := r.lookupGoType(reflect.TypeOf(value))
+	if entry == nil {
+		return errors.New(writer.Context().PrintWithContext("No extension registered for type '" +
+			typeName(reflect.TypeOf(value)) + "'"))
+	}
+	data, err := entry.encoder(value)
+	if err != nil {
+		return errors.New(writer.Context().PrintWithContext(err.Error()))
+	}
+	writer.WriteExt(entry.typ, data)
+	return nil
+}
+
+// Read decodes the next extension value with the decoder registered for its
+// type ID. Errors are *DecodeError values, including the ones of the decoder.
+func (r *ExtRegistry) Read(reader Read) (interface{}, error) {
+	rd, _ := reader.(*ReadDecoder)
+	offset := 0
+	if rd != nil {
+		offset = rd.view.Offset()
+	}
+	typ, data := reader.ReadExt()
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	entry := r.lookupType(typ)
+	if entry == nil {
+		return nil, extError(reader, "No extension registered for type "+strconv.Itoa(int(typ)), offset)
+	}
+	value, err := entry.decoder(data)
+	if err != nil {
+		return nil, extError(reader, err.Error(), offset)
+	}
+	if value == nil {
+		return nil, extError(reader, "Extension type "+strconv.Itoa(int(typ))+" decoded to nil", offset)
+	}
+	return value, nil
+}
+
+// extError fails the decoding of the extension at offset.
+func extError(reader Read, message string, offset int) error {
+	if rd, ok := reader.(*ReadDecoder); ok {
+		rd.fail(message, format.EXT32, rd.format, offset)
+		return rd.Err()
+	}
+	return &DecodeError{
+		Message:  message,
+		Context:  reader.Context().clone(),
+		Expected: format.EXT32,
+		Found:    format.EXT32,
+		Offset:   offset,
+	}
+}
@@ -0,0 +1,302 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+)
+
+func TestWriteReadExt(t *testing.T) {
+	cases := []struct {
+		name   string
+		length int
+		header []byte
+	}{
+		{"empty", 0, []byte{0xc7, 0}},
+		{"fixext1", 1, []byte{0xd4}},
+		{"fixext2", 2, []byte{0xd5}},
+		{"ext8", 3, []byte{0xc7, 3}},
+		{"fixext4", 4, []byte{0xd6}},
+		{"fixext8", 8, []byte{0xd7}},
+		{"fixext16", 16, []byte{0xd8}},
+		{"ext8 max", 255, []byte{0xc7, 255}},
+		{"ext16", 256, []byte{0xc8, 1, 0}},
+		{"ext32", 65536, []byte{0xc9, 0, 1, 0, 0}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte{0xab}, tcase.length)
+
+			writer := NewWriteEncoder(NewContext(""))
+			writer.WriteExt(42, data)
+
+			expected := append(append(append([]byte{}, tcase.header...), 42), data...)
+			if !bytes.Equal(writer.Buffer(), expected) {
+				t.Errorf("Bad value, got: %v, want: %v", writer.Buffer()[:len(tcase.header)+1], tcase.header)
+			}
+
+			reader := NewReadDecoder(NewContext(""), writer.Buffer())
+			typ, actual := reader.ReadExt()
+			if typ != 42 || !bytes.Equal(actual, data) {
+				t.Errorf("Bad ext, got: %d %v", typ, actual)
+			}
+		})
+	}
+}
+
+func TestReadExtWrongFormat(t *testing.T) {
+	reader := NewReadDecoderWithOptions(NewContext(""), []byte{0xa1, 0x61}, ReadOptions{})
+	reader.ReadExt()
+	if reader.Err() == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+type extPoint struct {
+	X, Y int32
+}
+
+func encodeExtPoint(value interface{}) ([]byte, error) {
+	p := value.(extPoint)
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint32(data, uint32(p.X))
+	binary.BigEndian.PutUint32(data[4:], uint32(p.Y))
+	return data, nil
+}
+
+func decodeExtPoint(data []byte) (interface{}, error) {
+	if len(data) != 8 {
+		return nil, errors.New("point must be 8 bytes")
+	}
+	return extPoint{
+		X: int32(binary.BigEndian.Uint32(data)),
+		Y: int32(binary.BigEndian.Uint32(data[4:])),
+	}, nil
+}
+
+func TestExtRegistry(t *testing.T) {
+	registry := NewExtRegistry()
+	if err := registry.Register(-1, extPoint{}, encodeExtPoint, decodeExtPoint); err == nil {
+		t.Errorf("Expected error for reserved type")
+	}
+	if err := registry.Register(ExtGenericMap, extPoint{}, encodeExtPoint, decodeExtPoint); err == nil {
+		t.Errorf("Expected error for reserved type")
+	}
+	if err := registry.Register(10, extPoint{}, encodeExtPoint, decodeExtPoint); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	if err := registry.Register(10, 0, encodeExtPoint, decodeExtPoint); err == nil {
+		t.Errorf("Expected error for duplicate type ID")
+	}
+	if err := registry.Register(11, extPoint{}, encodeExtPoint, decodeExtPoint); err == nil {
+		t.Errorf("Expected error for duplicate Go type")
+	}
+
+	writer := NewWriteEncoder(NewContext(""))
+	if err := registry.Write(writer, extPoint{X: 1, Y: -1}); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	if err := registry.Write(writer, "unregistered"); err == nil {
+		t.Errorf("Expected error for unregistered type")
+	}
+	expected := []byte{0xd7, 10, 0, 0, 0, 1, 0xff, 0xff, 0xff, 0xff}
+	if !bytes.Equal(writer.Buffer(), expected) {
+		t.Errorf("Bad value, got: %v, want: %v", writer.Buffer(), expected)
+	}
+
+	reader := NewReadDecoderWithOptions(NewContext(""), writer.Buffer(), ReadOptions{})
+	value, err := registry.Read(reader)
+	if err != nil {
+		t.Fatalf("Read error: %v", err)
+	}
+	if value != (extPoint{X: 1, Y: -1}) {
+		t.Errorf("Bad value, got: %v", value)
+	}
+
+	if err := registry.Register(12, extNil{}, nil, func(data []byte) (interface{}, error) { return nil, nil }); err != nil {
+		t.Fatalf("Register error: %v", err)
+	}
+	errorCases := []struct {
+		name  string
+		input []byte
+	}{
+		{"unregistered type ID", []byte{0xd4, 13, 0}},
+		{"decoder error", []byte{0xd4, 10, 0}},
+		{"decoded to nil", []byte{0xd4, 12, 0}},
+	}
+	for _, tcase := range errorCases {
+		reader = NewReadDecoderWithOptions(NewContext(""), tcase.input, ReadOptions{})
+		_, err := registry.Read(reader)
+		if _, ok := err.(*DecodeError); !ok {
+			t.Errorf("%s: expected a *DecodeError, got: %#v", tcase.name, err)
+		}
+	}
+}
+
+type extNil struct{}
+
+func TestUnmarshalExtNil(t *testing.T) {
+	if err := RegisterExt(101, extNil{}, nil, func(data []byte) (interface{}, error) { return nil, nil }); err != nil {
+		t.Fatalf("RegisterExt error: %v", err)
+	}
+
+	var value interface{}
+	err := Unmarshal([]byte{0xd4, 101, 0}, &value)
+	if _, ok := err.(*DecodeError); !ok {
+		t.Errorf("Expected a *DecodeError, got: %#v", err)
+	}
+	var ext extNil
+	err = Unmarshal([]byte{0xd4, 101, 0}, &ext)
+	if _, ok := err.(*DecodeError); !ok {
+		t.Errorf("Expected a *DecodeError, got: %#v", err)
+	}
+}
+
+type extLine struct {
+	From extPoint
+	To   *extPoint
+}
+
+func TestMarshalExt(t *testing.T) {
+	if err := RegisterExt(100, extPoint{}, encodeExtPoint, decodeExtPoint); err != nil {
+		t.Fatalf("RegisterExt error: %v", err)
+	}
+
+	expected := extLine{From: extPoint{X: 1, Y: 2}}
+	data, err := Marshal(expected)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var actual extLine
+	if err := Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if actual != expected {
+		t.Errorf("Bad value, got: %v, want: %v", actual, expected)
+	}
+}
+
+func writeStringI32(encoder Write, key interface{}, value interface{}) {
+	encoder.WriteString(key.(string))
+	encoder.WriteI32(value.(int32))
+}
+
+func readStringI32(reader Read) (interface{}, interface{}) {
+	return reader.ReadString(), reader.ReadI32()
+}
+
+func TestWriteExtGenericMap(t *testing.T) {
+	cases := []struct {
+		name  string
+		value container.Option
+		bytes []byte
+	}{
+		{"empty", container.Some(map[interface{}]interface{}{}), []byte{0xc7, 1, 1, 0x80}},
+		{"one entry", container.Some(map[interface{}]interface{}{"a": int32(1)}), []byte{0xc7, 4, 1, 0x81, 0xa1, 0x61, 1}},
+		{"optional nil", container.None(), []byte{0xc0}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			writer := NewWriteEncoder(NewContext(""))
+			writer.WriteOptionalExtGenericMap(tcase.value, writeStringI32)
+			if !bytes.Equal(writer.Buffer(), tcase.bytes) {
+				t.Errorf("Bad value, got: %v, want: %v", writer.Buffer(), tcase.bytes)
+			}
+
+			reader := NewReadDecoder(NewContext(""), writer.Buffer())
+			actual := reader.ReadOptionalExtGenericMap(readStringI32)
+			if !reflect.DeepEqual(actual, tcase.value) {
+				t.Errorf("Bad value, got: %v, want: %v", actual, tcase.value)
+			}
+		})
+	}
+}
+
+func TestWriteExtGenericMapLarge(t *testing.T) {
+	value := make(map[interface{}]interface{})
+	for i := 0; i < 100; i++ {
+		value[strconv.Itoa(i)] = int32(i * 1000)
+	}
+
+	sizer := NewWriteSizer(NewContext(""))
+	sizer.WriteExtGenericMap(value, writeStringI32)
+	writer := NewWriteEncoder(NewContext(""))
+	writer.WriteExtGenericMap(value, writeStringI32)
+
+	buf := writer.Buffer()
+	if int(sizer.Length()) != len(buf) {
+		t.Errorf("Bad length, got: %d, want: %d", sizer.Length(), len(buf))
+	}
+	if buf[0] != 0xc8 || int(binary.BigEndian.Uint16(buf[1:])) != len(buf)-4 || buf[3] != 1 {
+		t.Errorf("Bad header, got: %v", buf[:4])
+	}
+
+	reader := NewReadDecoder(NewContext(""), buf)
+	if actual := reader.ReadExtGenericMap(readStringI32); !reflect.DeepEqual(actual, value) {
+		t.Errorf("Bad value, got: %v, want: %v", actual, value)
+	}
+}
+
+func TestReadExtGenericMapErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		bytes []byte
+	}{
+		{"wrong ext type", []byte{0xc7, 4, 2, 0x81, 0xa1, 0x61, 1}},
+		{"length mismatch", []byte{0xc7, 5, 1, 0x81, 0xa1, 0x61, 1, 0xc0}},
+		{"bare map", []byte{0x81, 0xa1, 0x61, 1}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewReadDecoderWithOptions(NewContext(""), tcase.bytes, ReadOptions{})
+			reader.ReadExtGenericMap(readStringI32)
+			if reader.Err() == nil {
+				t.Errorf("Expected error")
+			}
+
+			var value map[string]int32
+			if tcase.name != "bare map" && Unmarshal(tcase.bytes, &value) == nil {
+				t.Errorf("Expected Unmarshal error")
+			}
+		})
+	}
+}
+
+type genericMapArgs struct {
+	Counts map[string]int32 `msgpack:"counts,genericmap"`
+	Plain  map[string]int32
+}
+
+func TestMarshalGenericMap(t *testing.T) {
+	expected := genericMapArgs{
+		Counts: map[string]int32{"a": 1},
+		Plain:  map[string]int32{"a": 1},
+	}
+	data, err := Marshal(expected)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if !bytes.Contains(data, []byte{0xa6, 'c', 'o', 'u', 'n', 't', 's', 0xc7, 4, 1, 0x81, 0xa1, 0x61, 1}) {
+		t.Errorf("GenericMap was not written: %v", data)
+	}
+
+	var actual genericMapArgs
+	if err := Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Bad value, got: %v, want: %v", actual, expected)
+	}
+}
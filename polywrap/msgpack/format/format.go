@@ -101,7 +101,19 @@ func ToString(f Format) string {
 		return "array"
 	case MAP16, MAP32:
 		return "map"
-	default:
-		return "unknown"
 	}
+
+	switch {
+	case f < FIXMAP:
+		return "positive fixint"
+	case f&FOUR_SIG_BITS_IN_BYTE == FIXMAP:
+		return "map"
+	case f&FOUR_SIG_BITS_IN_BYTE == FIXARRAY:
+		return "array"
+	case f < NIL:
+		return "string"
+	case f >= NEGATIVE_FIXINT:
+		return "negative fixint"
+	}
+	return "unknown"
 }
@@ -0,0 +1,175 @@
+//go:build go1.18 && !tinygo
+// +build go1.18,!tinygo
+
+package msgpack
+
+import (
+	"bytes"
+	"testing"
+)
+
+// readMethods reads an item with each Read method, keyed by the formats of the
+// readcase tables.
+var readMethods = map[string]func(reader *ReadDecoder){
+	"nil":        func(r *ReadDecoder) { r.IsNil() },
+	"bool":       func(r *ReadDecoder) { r.ReadBool() },
+	"bool?":      func(r *ReadDecoder) { r.ReadOptionalBool() },
+	"int8":       func(r *ReadDecoder) { r.ReadI8() },
+	"int8?":      func(r *ReadDecoder) { r.ReadOptionalI8() },
+	"int16":      func(r *ReadDecoder) { r.ReadI16() },
+	"int16?":     func(r *ReadDecoder) { r.ReadOptionalI16() },
+	"int32":      func(r *ReadDecoder) { r.ReadI32() },
+	"int32?":     func(r *ReadDecoder) { r.ReadOptionalI32() },
+	"int64":      func(r *ReadDecoder) { r.ReadI64() },
+	"int64?":     func(r *ReadDecoder) { r.ReadOptionalI64() },
+	"uint8":      func(r *ReadDecoder) { r.ReadU8() },
+	"uint8?":     func(r *ReadDecoder) { r.ReadOptionalU8() },
+	"uint16":     func(r *ReadDecoder) { r.ReadU16() },
+	"uint16?":    func(r *ReadDecoder) { r.ReadOptionalU16() },
+	"uint32":     func(r *ReadDecoder) { r.ReadU32() },
+	"uint32?":    func(r *ReadDecoder) { r.ReadOptionalU32() },
+	"uint64":     func(r *ReadDecoder) { r.ReadU64() },
+	"uint64?":    func(r *ReadDecoder) { r.ReadOptionalU64() },
+	"float32":    func(r *ReadDecoder) { r.ReadF32() },
+	"float32?":   func(r *ReadDecoder) { r.ReadOptionalF32() },
+	"float64":    func(r *ReadDecoder) { r.ReadF64() },
+	"float64?":   func(r *ReadDecoder) { r.ReadOptionalF64() },
+	"string":     func(r *ReadDecoder) { r.ReadString(); r.ReadStringView() },
+	"string?":    func(r *ReadDecoder) { r.ReadOptionalString() },
+	"bytes":      func(r *ReadDecoder) { r.ReadBytes(); r.ReadBytesView() },
+	"bytes?":     func(r *ReadDecoder) { r.ReadOptionalBytes() },
+	"array":      func(r *ReadDecoder) { r.ReadArray(fuzzReadItem) },
+	"array?":     func(r *ReadDecoder) { r.ReadOptionalArray(fuzzReadItem) },
+	"map":        func(r *ReadDecoder) { r.ReadMap(fuzzReadEntry) },
+	"map?":       func(r *ReadDecoder) { r.ReadOptionalMap(fuzzReadEntry) },
+	"bigint":     func(r *ReadDecoder) { r.ReadBigInt() },
+	"bigint?":    func(r *ReadDecoder) { r.ReadOptionalBigInt() },
+	"bignumber":  func(r *ReadDecoder) { r.ReadBigNumber() },
+	"bignumber?": func(r *ReadDecoder) { r.ReadOptionalBigNumber() },
+	"json":       func(r *ReadDecoder) { r.ReadJson() },
+	"json?":      func(r *ReadDecoder) { r.ReadOptionalJson() },
+	"ext":        func(r *ReadDecoder) { r.ReadExt() },
+	"genericmap": func(r *ReadDecoder) { r.ReadExtGenericMap(fuzzReadEntry) },
+	"value":      func(r *ReadDecoder) { r.ReadValue() },
+	"skip":       func(r *ReadDecoder) { r.Skip() },
+}
+
+var readCaseTables = [][]readcase{
+	isNilCases, readBoolCases, readI8Cases, readI16Cases, readI32Cases, readI64Cases,
+	readU8Cases, readU16Cases, readU32Cases, readU64Cases, readF32Cases, readF64Cases,
+	readBytesCases, readStringCases, readArrayCases, readMapCases, readBigIntCases, readBigNumberCases, readJsonCases,
+}
+
+func fuzzReadItem(reader Read) interface{} {
+	value, _ := reader.ReadValue()
+	return value
+}
+
+func fuzzReadEntry(reader Read) (interface{}, interface{}) {
+	return reader.ReadString(), fuzzReadItem(reader)
+}
+
+// fuzzRead checks that the Read methods for formats never panic and only fail
+// with a *DecodeError, with and without strict mode and limits.
+func fuzzRead(f *testing.F, formats ...string) {
+	for _, table := range readCaseTables {
+		for _, tcase := range table {
+			f.Add(tcase.bytes)
+		}
+	}
+	options := []ReadOptions{{}, {Strict: true}, {MaxDepth: 4, MaxArrayLength: 8, MaxMapLength: 8, MaxItems: 64}}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, format := range formats {
+			for _, option := range options {
+				reader := NewReadDecoderWithOptions(NewContext("Fuzzing "+format), data, option)
+				readMethods[format](reader)
+				reader.Finish()
+				if err := reader.Err(); err != nil {
+					if _, ok := err.(*DecodeError); !ok {
+						t.Fatalf("Bad error type %T: %v", err, err)
+					}
+				}
+			}
+		}
+	})
+}
+
+func FuzzIsNil(f *testing.F)         { fuzzRead(f, "nil") }
+func FuzzReadBool(f *testing.F)      { fuzzRead(f, "bool", "bool?") }
+func FuzzReadI8(f *testing.F)        { fuzzRead(f, "int8", "int8?") }
+func FuzzReadI16(f *testing.F)       { fuzzRead(f, "int16", "int16?") }
+func FuzzReadI32(f *testing.F)       { fuzzRead(f, "int32", "int32?") }
+func FuzzReadI64(f *testing.F)       { fuzzRead(f, "int64", "int64?") }
+func FuzzReadU8(f *testing.F)        { fuzzRead(f, "uint8", "uint8?") }
+func FuzzReadU16(f *testing.F)       { fuzzRead(f, "uint16", "uint16?") }
+func FuzzReadU32(f *testing.F)       { fuzzRead(f, "uint32", "uint32?") }
+func FuzzReadU64(f *testing.F)       { fuzzRead(f, "uint64", "uint64?") }
+func FuzzReadF32(f *testing.F)       { fuzzRead(f, "float32", "float32?") }
+func FuzzReadF64(f *testing.F)       { fuzzRead(f, "float64", "float64?") }
+func FuzzReadBytes(f *testing.F)     { fuzzRead(f, "bytes", "bytes?") }
+func FuzzReadString(f *testing.F)    { fuzzRead(f, "string", "string?") }
+func FuzzReadArray(f *testing.F)     { fuzzRead(f, "array", "array?") }
+func FuzzReadMap(f *testing.F)       { fuzzRead(f, "map", "map?") }
+func FuzzReadBigInt(f *testing.F)    { fuzzRead(f, "bigint", "bigint?") }
+func FuzzReadBigNumber(f *testing.F) { fuzzRead(f, "bignumber", "bignumber?") }
+func FuzzReadJson(f *testing.F)      { fuzzRead(f, "json", "json?") }
+func FuzzReadExt(f *testing.F)       { fuzzRead(f, "ext", "genericmap") }
+func FuzzReadValue(f *testing.F)     { fuzzRead(f, "value") }
+func FuzzSkip(f *testing.F)          { fuzzRead(f, "skip") }
+
+// FuzzRoundTrip decodes msgpack with ReadValue and checks that encoding the
+// value with a canonical WriteEncoder, decoding and encoding it again gives the
+// same bytes. Inputs with duplicate map keys are skipped, their entries collapse
+// once decoded, as are inputs ReadValue rejects.
+func FuzzRoundTrip(f *testing.F) {
+	for _, table := range readCaseTables {
+		for _, tcase := range table {
+			f.Add(tcase.bytes)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if _, err := Canonicalize(data); err != nil {
+			return
+		}
+		value, err := NewReadDecoderWithOptions(NewContext(""), data, ReadOptions{}).ReadValue()
+		if err != nil {
+			return
+		}
+		first := NewWriteEncoderWithOptions(NewContext(""), WriteOptions{Canonical: true})
+		first.WriteValue(value)
+
+		value, err = NewReadDecoderWithOptions(NewContext(""), first.Buffer(), ReadOptions{}).ReadValue()
+		if err != nil {
+			t.Fatalf("Can not decode encoded value %x: %v", first.Buffer(), err)
+		}
+		second := NewWriteEncoderWithOptions(NewContext(""), WriteOptions{Canonical: true})
+		second.WriteValue(value)
+
+		if !bytes.Equal(first.Buffer(), second.Buffer()) {
+			t.Fatalf("Unstable round trip, got: %x, want: %x", second.Buffer(), first.Buffer())
+		}
+	})
+}
+
+// FuzzUnmarshal checks that Unmarshal only fails with a *DecodeError.
+func FuzzUnmarshal(f *testing.F) {
+	for _, table := range readCaseTables {
+		for _, tcase := range table {
+			f.Add(tcase.bytes)
+		}
+	}
+	if data, err := Marshal(marshalSample{Str: "value", Items: []marshalNested{{Label: "label"}}}); err == nil {
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var v marshalSample
+		if err := Unmarshal(data, &v); err != nil {
+			if _, ok := err.(*DecodeError); !ok {
+				t.Fatalf("Bad error type %T: %v", err, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,386 @@
+package msgpack
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/json"
+	"github.com/valyala/fastjson"
+)
+
+// JSONBin selects how ToJSON writes binary data.
+type JSONBin int
+
+const (
+	// JSONBinBase64 writes binary data as standard base64 strings.
+	JSONBinBase64 JSONBin = iota
+	// JSONBinHex writes binary data as lowercase hex strings.
+	JSONBinHex
+)
+
+// JSONExt selects how ToJSON writes extension values. GenericMap extensions
+// are always written as the object they wrap.
+type JSONExt int
+
+const (
+	// JSONExtObject writes extensions as {"type": <id>, "data": <bin>}.
+	JSONExtObject JSONExt = iota
+	// JSONExtError fails on extensions.
+	JSONExtError
+)
+
+// JSONMapKeys selects how ToJSON writes map keys that are not strings.
+type JSONMapKeys int
+
+const (
+	// JSONMapKeysString converts nil, bool, number and binary keys to
+	// strings. Keys that collide once converted are an error.
+	JSONMapKeysString JSONMapKeys = iota
+	// JSONMapKeysError fails on keys that are not strings.
+	JSONMapKeysError
+)
+
+// JSONNumbers selects how numbers are converted.
+type JSONNumbers int
+
+const (
+	// JSONNumbersPreserve keeps integers and floats apart: ToJSON writes
+	// floats with a fraction or an exponent, FromJSON encodes numbers without
+	// them as integers and the others as float64.
+	JSONNumbersPreserve JSONNumbers = iota
+	// JSONNumbersFloat handles every JSON number as a float64, like
+	// encoding/json does. ToJSON writes integral floats without a fraction.
+	JSONNumbersFloat
+)
+
+// JSONOptions configures ToJSON and FromJSON. The zero value selects the
+// first option of each kind.
+type JSONOptions struct {
+	Bin     JSONBin
+	Ext     JSONExt
+	MapKeys JSONMapKeys
+	Numbers JSONNumbers
+}
+
+// ToJSON converts a msgpack value to JSON with the default options.
+func ToJSON(data []byte) ([]byte, error) {
+	return ToJSONWithOptions(data, JSONOptions{})
+}
+
+// ToJSONWithOptions converts a msgpack value to JSON. Map entries keep their
+// msgpack order. Trailing bytes after the value are an error.
+func ToJSONWithOptions(data []byte, options JSONOptions) ([]byte, error) {
+	context := NewContext("Converting msgpack to JSON")
+	reader := NewReadDecoderWithOptions(context, data, ReadOptions{})
+	converter := &jsonConverter{reader: reader, options: options}
+
+	converter.toJSON()
+	reader.checkEnd()
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
+	return converter.out, nil
+}
+
+// jsonConverter writes the JSON text of the values it reads to out, compact
+// like fastjson writes it.
+type jsonConverter struct {
+	reader  *ReadDecoder
+	out     []byte
+	options JSONOptions
+}
+
+func (c *jsonConverter) toJSON() {
+	reader := c.reader
+	f := reader.peekFormat()
+	if reader.err != nil || reader.view.Fill(1) == 0 {
+		// reports the end of input
+		reader.readFormat()
+		return
+	}
+	switch {
+	case f == format.NIL:
+		reader.readFormat()
+		c.out = append(c.out, "null"...)
+	case f == format.TRUE:
+		reader.readFormat()
+		c.out = append(c.out, "true"...)
+	case f == format.FALSE:
+		reader.readFormat()
+		c.out = append(c.out, "false"...)
+	case isFixedInt(uint8(f)), f == format.UINT8, f == format.UINT16, f == format.UINT32, f == format.UINT64:
+		c.out = strconv.AppendUint(c.out, reader.ReadU64(), 10)
+	case isNegativeFixedInt(uint8(f)), f == format.INT8, f == format.INT16, f == format.INT32, f == format.INT64:
+		c.out = strconv.AppendInt(c.out, reader.ReadI64(), 10)
+	case f == format.FLOAT32:
+		c.float(float64(reader.ReadF32()), 32)
+	case f == format.FLOAT64:
+		c.float(reader.ReadF64(), 64)
+	case isFixedString(uint8(f)), f == format.STR8, f == format.STR16, f == format.STR32:
+		c.out = appendJSONString(c.out, string(reader.ReadStringView()))
+	case f == format.BIN8, f == format.BIN16, f == format.BIN32:
+		ln := reader.ReadBytesLength()
+		c.out = appendJSONString(c.out, c.bin(reader.readBytesView(ln)))
+	case isFixedArray(uint8(f)), f == format.ARRAY16, f == format.ARRAY32:
+		size := reader.ReadArrayLength()
+		if !reader.enter() {
+			return
+		}
+		c.out = append(c.out, '[')
+		for i := 0; i < int(size) && reader.err == nil; i++ {
+			if i > 0 {
+				c.out = append(c.out, ',')
+			}
+			c.toJSON()
+		}
+		c.out = append(c.out, ']')
+		reader.leave()
+	case isFixedMap(uint8(f)), f == format.MAP16, f == format.MAP32:
+		c.object()
+	case isExt(f):
+		c.ext()
+	default:
+		reader.readFormat()
+		reader.unexpected("Unknown format "+format.ToString(f), format.ERROR)
+	}
+}
+
+// appendJSONString appends s as a JSON string. Control characters are
+// written as \u escapes and invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, s[start:i]...)
+				dst = append(dst, `\ufffd`...)
+				start = i + size
+			}
+			i += size
+			continue
+		}
+		if b >= 0x20 && b != '"' && b != '\\' {
+			i++
+			continue
+		}
+		dst = append(dst, s[start:i]...)
+		switch b {
+		case '"', '\\':
+			dst = append(dst, '\\', b)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+		}
+		i++
+		start = i
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
+
+func (c *jsonConverter) float(value float64, bitSize int) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		c.reader.unexpected("Float "+strconv.FormatFloat(value, 'g', -1, 64)+" can not be represented in JSON", format.FLOAT64)
+		return
+	}
+	s := strconv.FormatFloat(value, 'g', -1, bitSize)
+	if c.options.Numbers == JSONNumbersPreserve && !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	c.out = append(c.out, s...)
+}
+
+func (c *jsonConverter) bin(data []byte) string {
+	if c.options.Bin == JSONBinHex {
+		return hex.EncodeToString(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func (c *jsonConverter) object() {
+	reader := c.reader
+	size := reader.ReadMapLength()
+	if !reader.enter() {
+		return
+	}
+	defer reader.leave()
+	// duplicate keys are found in constant time
+	seen := make(map[string]struct{}, reader.capacity(size))
+	c.out = append(c.out, '{')
+	for i := uint32(0); i < size && reader.err == nil; i++ {
+		offset := reader.view.Offset()
+		key := c.key()
+		if reader.err != nil {
+			break
+		}
+		if _, ok := seen[key]; ok {
+			reader.fail("Duplicate map key '"+key+"'", format.ERROR, reader.format, offset)
+			break
+		}
+		seen[key] = struct{}{}
+		if i > 0 {
+			c.out = append(c.out, ',')
+		}
+		c.out = appendJSONString(c.out, key)
+		c.out = append(c.out, ':')
+		c.toJSON()
+	}
+	c.out = append(c.out, '}')
+}
+
+func (c *jsonConverter) key() string {
+	reader := c.reader
+	f := reader.peekFormat()
+	if isFixedString(uint8(f)) || f == format.STR8 || f == format.STR16 || f == format.STR32 {
+		return reader.ReadString()
+	}
+
+	offset := reader.view.Offset()
+	if c.options.MapKeys == JSONMapKeysError {
+		reader.readFormat()
+		reader.unexpected("Map keys must be strings. Found "+format.ToString(f), format.STR32)
+		return ""
+	}
+	// converts the key and takes its JSON text back from out
+	start := len(c.out)
+	c.toJSON()
+	key := string(c.out[start:])
+	c.out = c.out[:start]
+	if reader.err != nil {
+		return ""
+	}
+	switch key[0] {
+	case '[', '{':
+		reader.fail("Map keys must be scalar values", format.ERROR, reader.format, offset)
+		return ""
+	case '"':
+		// binary keys, whose encoding needs no escaping
+		return key[1 : len(key)-1]
+	}
+	return key
+}
+
+func (c *jsonConverter) ext() {
+	reader := c.reader
+	ln := reader.readExtLength()
+	f, offset := reader.format, reader.offset
+	typ := reader.readInt8()
+	if reader.err != nil {
+		return
+	}
+
+	if typ == ExtGenericMap {
+		start := reader.view.Offset()
+		c.object()
+		if reader.err == nil && reader.view.Offset()-start != int(ln) {
+			reader.fail("GenericMap extension length mismatch", format.EXT32, f, offset)
+		}
+		return
+	}
+	if c.options.Ext == JSONExtError {
+		reader.fail("Extension type "+strconv.Itoa(int(typ))+" can not be converted to JSON", format.ERROR, f, offset)
+		return
+	}
+
+	c.out = append(c.out, `{"type":`...)
+	c.out = strconv.AppendInt(c.out, int64(typ), 10)
+	c.out = append(c.out, `,"data":`...)
+	c.out = appendJSONString(c.out, c.bin(reader.readBytesView(ln)))
+	c.out = append(c.out, '}')
+}
+
+// FromJSON converts a JSON value to msgpack with the default options.
+func FromJSON(data []byte) ([]byte, error) {
+	return FromJSONWithOptions(data, JSONOptions{})
+}
+
+// FromJSONWithOptions converts a JSON value to msgpack. Objects become maps
+// with their keys in document order and strings are always written as
+// msgpack strings. Only Numbers applies, the other options are for ToJSON.
+func FromJSONWithOptions(data []byte, options JSONOptions) ([]byte, error) {
+	value, err := json.Decode(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	encoder := NewWriteEncoder(NewContext("Converting JSON to msgpack"))
+	if err := fromJSON(encoder, value.Value, options); err != nil {
+		return nil, err
+	}
+	return encoder.Buffer(), nil
+}
+
+func fromJSON(writer Write, value *fastjson.Value, options JSONOptions) error {
+	switch value.Type() {
+	case fastjson.TypeNull:
+		writer.WriteNil()
+	case fastjson.TypeTrue:
+		writer.WriteBool(true)
+	case fastjson.TypeFalse:
+		writer.WriteBool(false)
+	case fastjson.TypeNumber:
+		return fromJSONNumber(writer, value.String(), options)
+	case fastjson.TypeString:
+		writer.WriteString(string(value.GetStringBytes()))
+	case fastjson.TypeArray:
+		items := value.GetArray()
+		writer.WriteArrayLength(uint32(len(items)))
+		for i := range items {
+			writer.Context().Push(strconv.Itoa(i), "array item", "converting JSON")
+			if err := fromJSON(writer, items[i], options); err != nil {
+				return err
+			}
+			writer.Context().Pop()
+		}
+	case fastjson.TypeObject:
+		object := value.GetObject()
+		writer.WriteMapLength(uint32(object.Len()))
+		var err error
+		object.Visit(func(key []byte, item *fastjson.Value) {
+			if err != nil {
+				return
+			}
+			writer.Context().Push(string(key), "object property", "converting JSON")
+			writer.WriteString(string(key))
+			if err = fromJSON(writer, item, options); err == nil {
+				writer.Context().Pop()
+			}
+		})
+		return err
+	}
+	return nil
+}
+
+func fromJSONNumber(writer Write, raw string, options JSONOptions) error {
+	if options.Numbers == JSONNumbersPreserve && !strings.ContainsAny(raw, ".eE") {
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			writer.WriteI64(v)
+			return nil
+		}
+		if v, err := strconv.ParseUint(raw, 10, 64); err == nil {
+			writer.WriteU64(v)
+			return nil
+		}
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return errors.New(writer.Context().PrintWithContext("Invalid number '" + raw + "': " + err.Error()))
+	}
+	writer.WriteFloat64(v)
+	return nil
+}
@@ -0,0 +1,145 @@
+package msgpack
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"strconv"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    []byte
+		options JSONOptions
+		want    string
+	}{
+		{"scalars", []byte{0x94, 0xc0, 0xc3, 0xff, 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			JSONOptions{}, `[null,true,-1,18446744073709551615]`},
+		{"float preserved", []byte{0x92, 0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0, 0xca, 0x3f, 0xc0, 0, 0},
+			JSONOptions{}, `[1.0,1.5]`},
+		{"float as number", []byte{0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0},
+			JSONOptions{Numbers: JSONNumbersFloat}, `1`},
+		{"string", []byte{0xa3, 'a', '"', 'b'}, JSONOptions{}, `"a\"b"`},
+		{"control characters", []byte{0xa6, 'a', 0x01, 'b', 0x07, '\n', '\\'}, JSONOptions{},
+			`"a\u0001b\u0007\n\\"`},
+		{"non-printable rune", []byte{0xa5, 0xf3, 0xa0, 0x80, 0x81, '"'}, JSONOptions{}, "\"\U000E0001\\\"\""},
+		{"invalid utf-8", []byte{0xa3, 0xff, 0x01, 'a'}, JSONOptions{}, `"\ufffd\u0001a"`},
+		{"bin base64", []byte{0xc4, 0x03, 0x01, 0x02, 0x03}, JSONOptions{}, `"AQID"`},
+		{"bin hex", []byte{0xc4, 0x03, 0x01, 0x02, 0x03}, JSONOptions{Bin: JSONBinHex}, `"010203"`},
+		{"map", []byte{0x82, 0xa1, 'b', 0x01, 0xa1, 'a', 0x90}, JSONOptions{}, `{"b":1,"a":[]}`},
+		{"map int keys", []byte{0x82, 0x01, 0xa1, 'a', 0xc2, 0xa1, 'b'}, JSONOptions{}, `{"1":"a","false":"b"}`},
+		{"generic map", []byte{0xc7, 0x04, 0x01, 0x81, 0x07, 0xa1, 'x'}, JSONOptions{}, `{"7":"x"}`},
+		{"ext", []byte{0xd4, 0x05, 0xff}, JSONOptions{Bin: JSONBinHex}, `{"type":5,"data":"ff"}`},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			actual, err := ToJSONWithOptions(tcase.data, tcase.options)
+			if err != nil {
+				t.Fatalf("ToJSON error: %v", err)
+			}
+			if string(actual) != tcase.want {
+				t.Errorf("Bad value, got: %s, want: %s", actual, tcase.want)
+			}
+			if !stdjson.Valid(actual) {
+				t.Errorf("Invalid JSON: %s", actual)
+			}
+		})
+	}
+}
+
+func TestToJSONErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    []byte
+		options JSONOptions
+	}{
+		{"truncated", []byte{0x92, 0x01}, JSONOptions{}},
+		{"trailing bytes", []byte{0x01, 0x02}, JSONOptions{}},
+		{"nan", []byte{0xcb, 0x7f, 0xf8, 0, 0, 0, 0, 0, 0x01}, JSONOptions{}},
+		{"duplicate key", []byte{0x82, 0xa1, 'a', 0xc0, 0xa1, 'a', 0xc0}, JSONOptions{}},
+		{"key collision", []byte{0x82, 0x01, 0xc0, 0xa1, '1', 0xc0}, JSONOptions{}},
+		{"non-string key", []byte{0x81, 0x01, 0xc0}, JSONOptions{MapKeys: JSONMapKeysError}},
+		{"array key", []byte{0x81, 0x90, 0xc0}, JSONOptions{}},
+		{"ext", []byte{0xd4, 0x05, 0xff}, JSONOptions{Ext: JSONExtError}},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if _, err := ToJSONWithOptions(tcase.data, tcase.options); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestToJSONLargeMap(t *testing.T) {
+	// duplicate keys are found in constant time
+	const size = 100000
+	encoder := NewWriteEncoder(NewContext(""))
+	encoder.WriteMapLength(size)
+	for i := 0; i < size; i++ {
+		encoder.WriteString(strconv.Itoa(i))
+		encoder.WriteNil()
+	}
+	data, err := ToJSON(encoder.Buffer())
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	if !bytes.HasSuffix(data, []byte(`"99999":null}`)) {
+		t.Errorf("Bad value, got: %s", data[len(data)-20:])
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		json    string
+		options JSONOptions
+		want    []byte
+	}{
+		{"null", `null`, JSONOptions{}, []byte{0xc0}},
+		{"int", `-5`, JSONOptions{}, []byte{0xfb}},
+		{"uint64", `18446744073709551615`, JSONOptions{},
+			[]byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"float", `1.0`, JSONOptions{}, []byte{0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		{"int as float", `1`, JSONOptions{Numbers: JSONNumbersFloat}, []byte{0xcb, 0x3f, 0xf0, 0, 0, 0, 0, 0, 0}},
+		{"object", `{"b": [true], "a": "x"}`, JSONOptions{},
+			[]byte{0x82, 0xa1, 'b', 0x91, 0xc3, 0xa1, 'a', 0xa1, 'x'}},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			actual, err := FromJSONWithOptions([]byte(tcase.json), tcase.options)
+			if err != nil {
+				t.Fatalf("FromJSON error: %v", err)
+			}
+			if !bytes.Equal(actual, tcase.want) {
+				t.Errorf("Bad value, got: %x, want: %x", actual, tcase.want)
+			}
+		})
+	}
+
+	if _, err := FromJSON([]byte(`{"a": `)); err == nil {
+		t.Errorf("Expected error for invalid JSON")
+	}
+	if _, err := FromJSON([]byte(`1e400`)); err == nil {
+		t.Errorf("Expected error for out of range number")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	input := `{"name":"wrap\u0001\n","version":1,"ratio":0.5,"tags":["a","b"],"nested":{"ok":true,"none":null}}`
+	data, err := FromJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("FromJSON error: %v", err)
+	}
+	output, err := ToJSON(data)
+	if err != nil {
+		t.Fatalf("ToJSON error: %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("Bad round trip, got: %s, want: %s", output, input)
+	}
+}
@@ -0,0 +1,153 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadLimits(t *testing.T) {
+	nested := []byte{0x91, 0x91, 0x91, 0x01}
+	readItem := func(r Read) interface{} { return r.ReadI32() }
+
+	cases := []struct {
+		name    string
+		data    []byte
+		options ReadOptions
+		read    func(reader *ReadDecoder)
+		message string
+	}{
+		{"huge array", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}, ReadOptions{},
+			func(r *ReadDecoder) { r.ReadArray(readItem) }, "exceeds the remaining input"},
+		{"huge map", []byte{0xdf, 0xff, 0xff, 0xff, 0xff, 0x01}, ReadOptions{},
+			func(r *ReadDecoder) { r.ReadValue() }, "exceeds the remaining input"},
+		{"array length", []byte{0x93, 0x01, 0x02, 0x03}, ReadOptions{MaxArrayLength: 2},
+			func(r *ReadDecoder) { r.ReadArray(readItem) }, "Array length 3 exceeds the limit of 2"},
+		{"map length", []byte{0x82, 0x01, 0x01, 0x02, 0x02}, ReadOptions{MaxMapLength: 1},
+			func(r *ReadDecoder) { r.ReadMapLength() }, "Map length 2 exceeds the limit of 1"},
+		{"string length", []byte{0xa3, 'a', 'b', 'c'}, ReadOptions{MaxStringLength: 2},
+			func(r *ReadDecoder) { r.ReadString() }, "String length 3 exceeds the limit of 2"},
+		{"bytes length", []byte{0xc4, 0x03, 1, 2, 3}, ReadOptions{MaxBytesLength: 2},
+			func(r *ReadDecoder) { r.ReadBytes() }, "Binary length 3 exceeds the limit of 2"},
+		{"ext length", []byte{0xd6, 0x05, 1, 2, 3, 4}, ReadOptions{MaxBytesLength: 2},
+			func(r *ReadDecoder) { r.ReadExt() }, "Extension length 4 exceeds the limit of 2"},
+		{"depth", nested, ReadOptions{MaxDepth: 2},
+			func(r *ReadDecoder) { r.ReadValue() }, "maximum nesting depth of 2"},
+		{"items", []byte{0x94, 0x01, 0x02, 0x03, 0x04}, ReadOptions{MaxItems: 3},
+			func(r *ReadDecoder) { r.ReadValue() }, "Array length 4 exceeds the limit of 3 items"},
+		{"items in sequence", []byte{0x01, 0x02, 0x03}, ReadOptions{MaxItems: 2},
+			func(r *ReadDecoder) { r.ReadU8(); r.ReadU8(); r.ReadU8() }, "Input exceeds the limit of 2 items"},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewReadDecoderWithOptions(NewContext("Deserializing"), tcase.data, tcase.options)
+			tcase.read(reader)
+			err := reader.Err()
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if !strings.Contains(err.Error(), tcase.message) {
+				t.Errorf("Bad error, got: %v, want: %v", err, tcase.message)
+			}
+		})
+	}
+}
+
+func TestReadLimitsAllowValidInput(t *testing.T) {
+	options := ReadOptions{MaxDepth: 3, MaxArrayLength: 1, MaxItems: 4}
+	reader := NewReadDecoderWithOptions(NewContext(""), []byte{0x91, 0x91, 0x91, 0x01}, options)
+	if _, err := reader.ReadValue(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	var v [][]int32
+	if err := UnmarshalWithOptions([]byte{0x91, 0x91, 0x01}, &v, ReadOptions{MaxDepth: 1}); err == nil {
+		t.Errorf("Expected depth error")
+	}
+	if err := UnmarshalWithOptions([]byte{0x91, 0x91, 0x01}, &v, ReadOptions{MaxDepth: 2}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestReadDefaultMaxDepth(t *testing.T) {
+	// nesting far beyond the default depth would overflow the stack
+	deepArray := append(bytes.Repeat([]byte{0x91}, 1<<20), 0xc0)
+	deepMap := append(bytes.Repeat([]byte{0x81, 0xc0}, 1<<20), 0xc0)
+	allowed := append(bytes.Repeat([]byte{0x91}, DefaultMaxDepth), 0xc0)
+
+	cases := []struct {
+		name string
+		read func(data []byte) error
+	}{
+		{"ReadValue", func(data []byte) error {
+			_, err := NewReadDecoderWithOptions(NewContext(""), data, ReadOptions{}).ReadValue()
+			return err
+		}},
+		{"ToJSON", func(data []byte) error { _, err := ToJSON(data); return err }},
+		{"Canonicalize", func(data []byte) error { _, err := Canonicalize(data); return err }},
+		{"Dump", func(data []byte) error {
+			if dump := Dump(data); strings.Contains(dump, "error at") {
+				return errors.New(dump[strings.Index(dump, "error at"):])
+			}
+			return nil
+		}},
+		{"Unmarshal", func(data []byte) error { var v interface{}; return Unmarshal(data, &v) }},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			for _, data := range [][]byte{deepArray, deepMap} {
+				err := tcase.read(data)
+				if err == nil || !strings.Contains(err.Error(), "maximum nesting depth of 512") {
+					t.Errorf("Bad error, got: %v, want: maximum nesting depth of 512", err)
+				}
+			}
+			if err := tcase.read(allowed); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalLargeElements(t *testing.T) {
+	if runtime.Compiler == "tinygo" {
+		t.Log("Skipping due tinygo limitations")
+		return
+	}
+	// one junk byte per announced element passes the length check, but the
+	// elements themselves are much larger than the input
+	const size = 100000
+	data := make([]byte, 5, 5+size)
+	data[0] = 0xdd
+	binary.BigEndian.PutUint32(data[1:], size)
+	data = append(data, bytes.Repeat([]byte{0xc1}, size)...)
+	mapData := append([]byte{0xdf}, data[1:]...)
+
+	type large struct{ A [4096]byte }
+	cases := []struct {
+		name   string
+		data   []byte
+		target interface{}
+	}{
+		{"slice", data, new([]large)},
+		{"map", mapData, new(map[int32]large)},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			if err := Unmarshal(tcase.data, tcase.target); err == nil {
+				t.Fatalf("Expected error")
+			}
+			runtime.ReadMemStats(&after)
+			if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 4*size {
+				t.Errorf("Allocated %v bytes for %v bytes of input", allocated, len(tcase.data))
+			}
+		})
+	}
+}
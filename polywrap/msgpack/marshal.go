@@ -0,0 +1,267 @@
+package msgpack
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/valyala/fastjson"
+)
+
+var (
+	bigIntType    = reflect.TypeOf(big.Int{})
+	bigNumberType = reflect.TypeOf(big.Number{})
+	jsonValueType = reflect.TypeOf(fastjson.Value{})
+)
+
+// Marshal returns the msgpack encoding of v.
+//
+// Structs are encoded as maps keyed by the `msgpack:"name,omitempty"` tag of
+// each exported field. Fields without a tag use the field name with its first
+// letter lowercased, matching Polywrap schema property names. Map fields
+// tagged with the "genericmap" option are wrapped in a GenericMap extension,
+// as the schema type Map<K, V> requires. Nil pointers,
+// slices and maps are encoded as nil, so pointers can be used for optional
+// values. *big.Int, *big.Number and *fastjson.Value are encoded like
+// WriteBigInt, WriteBigNumber and WriteJson do, and types registered in DefaultExtRegistry as extensions.
+func Marshal(v interface{}) ([]byte, error) {
+	return MarshalWithOptions(v, WriteOptions{})
+}
+
+// MarshalWithOptions is Marshal with encoder options, e.g. Canonical for a
+// deterministic encoding.
+func MarshalWithOptions(v interface{}, options WriteOptions) ([]byte, error) {
+	context := NewContext("Marshaling (encoding) " + typeName(reflect.TypeOf(v)))
+	encoder := NewWriteEncoderWithOptions(context, options)
+	if err := marshalValue(encoder, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return encoder.Buffer(), nil
+}
+
+func marshalValue(writer Write, v reflect.Value) error {
+	if !v.IsValid() {
+		writer.WriteNil()
+		return nil
+	}
+
+	if DefaultExtRegistry.lookupGoType(v.Type()) != nil {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			writer.WriteNil()
+			return nil
+		}
+		return DefaultExtRegistry.Write(writer, v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			writer.WriteNil()
+			return nil
+		}
+		switch v.Type().Elem() {
+		case bigIntType:
+			writer.WriteBigInt(v.Interface().(*big.Int))
+			return nil
+		case bigNumberType:
+			writer.WriteBigNumber(v.Interface().(*big.Number))
+			return nil
+		case jsonValueType:
+			writer.WriteJson(v.Interface().(*fastjson.Value))
+			return nil
+		}
+		return marshalValue(writer, v.Elem())
+	case reflect.Interface:
+		return marshalValue(writer, v.Elem())
+	case reflect.Bool:
+		writer.WriteBool(v.Bool())
+	case reflect.Int8:
+		writer.WriteI8(int8(v.Int()))
+	case reflect.Int16:
+		writer.WriteI16(int16(v.Int()))
+	case reflect.Int32:
+		writer.WriteI32(int32(v.Int()))
+	case reflect.Int, reflect.Int64:
+		writer.WriteI64(v.Int())
+	case reflect.Uint8:
+		writer.WriteU8(uint8(v.Uint()))
+	case reflect.Uint16:
+		writer.WriteU16(uint16(v.Uint()))
+	case reflect.Uint32:
+		writer.WriteU32(uint32(v.Uint()))
+	case reflect.Uint, reflect.Uint64:
+		writer.WriteU64(v.Uint())
+	case reflect.Float32:
+		writer.WriteFloat32(float32(v.Float()))
+	case reflect.Float64:
+		writer.WriteFloat64(v.Float())
+	case reflect.String:
+		writer.WriteString(v.String())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			writer.WriteBytes(v.Bytes())
+			return nil
+		}
+		if v.Len() == 0 {
+			writer.WriteNil()
+			return nil
+		}
+		return marshalArray(writer, v)
+	case reflect.Array:
+		return marshalArray(writer, v)
+	case reflect.Map:
+		if v.IsNil() {
+			writer.WriteNil()
+			return nil
+		}
+		return marshalMap(writer, v)
+	case reflect.Struct:
+		if v.Type() == bigIntType {
+			value := v.Interface().(big.Int)
+			writer.WriteBigInt(&value)
+			return nil
+		}
+		if v.Type() == bigNumberType {
+			value := v.Interface().(big.Number)
+			writer.WriteBigNumber(&value)
+			return nil
+		}
+		return marshalStruct(writer, v)
+	default:
+		return errors.New(writer.Context().PrintWithContext("Unsupported type '" + typeName(v.Type()) + "'"))
+	}
+	return nil
+}
+
+func marshalArray(writer Write, v reflect.Value) error {
+	writer.WriteArrayLength(uint32(v.Len()))
+	for i := 0; i < v.Len(); i++ {
+		writer.Context().Push(strconv.Itoa(i), typeName(v.Type().Elem()), "writing array item")
+		if err := marshalValue(writer, v.Index(i)); err != nil {
+			return err
+		}
+		writer.Context().Pop()
+	}
+	return nil
+}
+
+// marshalMap goes through WriteMap, so that canonical encoders can sort the
+// entries.
+func marshalMap(writer Write, v reflect.Value) error {
+	var err error
+	writer.WriteMap(mapEntries(v), marshalMapEntry(&err))
+	return err
+}
+
+func marshalStruct(writer Write, v reflect.Value) error {
+	fields := structFields(v.Type())
+
+	length := uint32(0)
+	for i := range fields {
+		if !fields[i].omitEmpty || !v.Field(fields[i].index).IsZero() {
+			length++
+		}
+	}
+
+	writer.WriteMapLength(length)
+	for i := range fields {
+		field := v.Field(fields[i].index)
+		if fields[i].omitEmpty && field.IsZero() {
+			continue
+		}
+		writer.Context().Push(fields[i].name, typeName(field.Type()), "writing property")
+		writer.WriteString(fields[i].name)
+		var err error
+		if fields[i].genericMap && field.Kind() == reflect.Map && !field.IsNil() {
+			err = marshalGenericMap(writer, field)
+		} else {
+			err = marshalValue(writer, field)
+		}
+		if err != nil {
+			return err
+		}
+		writer.Context().Pop()
+	}
+	return nil
+}
+
+func marshalGenericMap(writer Write, v reflect.Value) error {
+	var err error
+	writer.WriteExtGenericMap(mapEntries(v), marshalMapEntry(&err))
+	return err
+}
+
+func mapEntries(v reflect.Value) map[interface{}]interface{} {
+	entries := make(map[interface{}]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		entries[iter.Key().Interface()] = iter.Value().Interface()
+	}
+	return entries
+}
+
+// marshalMapEntry returns a WriteMap callback that stores the first error in
+// err and skips the remaining entries.
+func marshalMapEntry(err *error) func(encoder Write, key interface{}, value interface{}) {
+	return func(encoder Write, key interface{}, value interface{}) {
+		if *err != nil {
+			return
+		}
+		if *err = marshalValue(encoder, reflect.ValueOf(key)); *err == nil {
+			*err = marshalValue(encoder, reflect.ValueOf(value))
+		}
+	}
+}
+
+type structField struct {
+	name       string
+	index      int
+	omitEmpty  bool
+	genericMap bool
+}
+
+func structFields(t reflect.Type) []structField {
+	fields := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// unexported
+			continue
+		}
+		tag := f.Tag.Get("msgpack")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		field := structField{name: opts[0], index: i}
+		if field.name == "" {
+			field.name = lowerFirst(f.Name)
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "genericmap":
+				field.genericMap = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
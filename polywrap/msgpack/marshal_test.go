@@ -0,0 +1,187 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/valyala/fastjson"
+)
+
+type marshalNested struct {
+	Label string
+	Tags  []string
+}
+
+type marshalSample struct {
+	Str      string
+	I8       int8
+	I64      int64 `msgpack:"int64"`
+	U32      uint32
+	F64      float64
+	Flag     bool
+	Raw      []byte
+	Items    []marshalNested
+	Counts   map[string]int32
+	Optional *int32
+	Missing  *string `msgpack:"missing,omitempty"`
+	Big      *big.Int
+	Decimal  *big.Number
+	Json     *fastjson.Value
+	Skipped  string `msgpack:"-"`
+	private  string
+}
+
+func TestMarshalMatchesEncoder(t *testing.T) {
+	type args struct {
+		Arg string
+	}
+
+	actual, err := Marshal(args{Arg: "42"})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	encoder := NewWriteEncoder(NewContext(""))
+	encoder.WriteMapLength(1)
+	encoder.WriteString("arg")
+	encoder.WriteString("42")
+
+	if !bytes.Equal(actual, encoder.Buffer()) {
+		t.Errorf("Bad value, got: %v, want: %v", actual, encoder.Buffer())
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	optional := int32(-7)
+	expected := marshalSample{
+		Str:      "value",
+		I8:       -3,
+		I64:      -1 << 40,
+		U32:      1 << 20,
+		F64:      3.5,
+		Flag:     true,
+		Raw:      []byte{1, 2, 3},
+		Items:    []marshalNested{{Label: "first", Tags: []string{"a", "b"}}, {Label: "second"}},
+		Counts:   map[string]int32{"one": 1, "two": 2},
+		Optional: &optional,
+		Big:      big.NewInt(100500),
+		Decimal:  big.NewNumber(100500, -3),
+		Json:     fastjson.MustParse(`{"key":"value"}`),
+		Skipped:  "skipped",
+		private:  "private",
+	}
+
+	data, err := Marshal(&expected)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var actual marshalSample
+	if err := Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	if actual.Json.String() != expected.Json.String() {
+		t.Errorf("Bad json, got: %v, want: %v", actual.Json, expected.Json)
+	}
+	if actual.Big.Cmp(expected.Big) != 0 {
+		t.Errorf("Bad bigint, got: %v, want: %v", actual.Big, expected.Big)
+	}
+	actual.Json, expected.Json = nil, nil
+	actual.Big, expected.Big = nil, nil
+	expected.Skipped, expected.private = "", ""
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Bad value, got: %+v, want: %+v", actual, expected)
+	}
+}
+
+func TestMarshalOmitEmpty(t *testing.T) {
+	data, err := Marshal(marshalSample{})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	// 13 fields, "missing" is omitted
+	if data[0] != 0x8d {
+		t.Errorf("Bad map length, got: %x, want: %x", data[0], 0x8d)
+	}
+	if bytes.Contains(data, []byte("missing")) {
+		t.Errorf("Omitted field was written: %v", data)
+	}
+}
+
+func TestMarshalStrictRoundTrip(t *testing.T) {
+	expected := marshalSample{
+		Raw:    []byte{},
+		Items:  []marshalNested{{}},
+		Counts: map[string]int32{},
+	}
+	data, err := Marshal(&expected)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var actual marshalSample
+	if err := UnmarshalWithOptions(data, &actual, ReadOptions{Strict: true}); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if len(actual.Raw) != 0 || len(actual.Items) != 1 || len(actual.Items[0].Tags) != 0 ||
+		actual.Counts == nil || len(actual.Counts) != 0 {
+		t.Errorf("Bad value, got: %+v, want: %+v", actual, expected)
+	}
+}
+
+func TestUnmarshalStrictNil(t *testing.T) {
+	cases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"string", new(string)},
+		{"int", new(int32)},
+		{"map", new(map[string]int32)},
+		{"struct", new(marshalNested)},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			if err := Unmarshal([]byte{0xc0}, tcase.value); err != nil {
+				t.Errorf("Unexpected lenient error: %v", err)
+			}
+			err := UnmarshalWithOptions([]byte{0xc0}, tcase.value, ReadOptions{Strict: true})
+			if _, ok := err.(*DecodeError); !ok {
+				t.Errorf("Expected a *DecodeError, got: %#v", err)
+			}
+		})
+	}
+
+	// optional values and the nil written for empty bytes and arrays
+	var optional struct {
+		Ptr   *int32
+		Bytes []byte
+		Array []int32
+		Any   interface{}
+	}
+	if err := UnmarshalWithOptions([]byte{0x84, 0xa3, 'p', 't', 'r', 0xc0, 0xa5, 'b', 'y', 't', 'e', 's', 0xc0,
+		0xa5, 'a', 'r', 'r', 'a', 'y', 0xc0, 0xa3, 'a', 'n', 'y', 0xc0}, &optional, ReadOptions{Strict: true}); err != nil {
+		t.Errorf("Unexpected strict error: %v", err)
+	}
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	if _, err := Marshal(make(chan int)); err == nil {
+		t.Errorf("Expected error for unsupported type")
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var s marshalNested
+	if err := Unmarshal([]byte{0x81, 0xa5, 'l', 'a', 'b', 'e', 'l', 0xc3}, &s); err == nil {
+		t.Errorf("Expected error for type mismatch")
+	}
+	if err := Unmarshal([]byte{0x80}, s); err == nil {
+		t.Errorf("Expected error for non-pointer value")
+	}
+}
@@ -0,0 +1,157 @@
+package msgpack
+
+import (
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+	"github.com/valyala/fastjson"
+)
+
+// ReadOption reads nil as None and any other item with fn. It is the typed
+// counterpart of the ReadOptional methods, e.g. ReadOptionI32 returns a
+// container.OptionOf[int32] where ReadOptionalI32 returns a container.Option
+// holding an interface{}.
+func ReadOption[T any](reader Read, fn func(reader Read) T) container.OptionOf[T] {
+	if reader.IsNil() {
+		reader.Skip()
+		return container.NoneOf[T]()
+	}
+	return container.SomeOf(fn(reader))
+}
+
+// WriteOption writes None as nil and the value of Some with fn.
+func WriteOption[T any](writer Write, value container.OptionOf[T], fn func(writer Write, value T)) {
+	v, ok := value.Get()
+	if !ok {
+		writer.WriteNil()
+		return
+	}
+	fn(writer, v)
+}
+
+func ReadOptionBool(reader Read) container.OptionOf[bool] {
+	return ReadOption(reader, Read.ReadBool)
+}
+
+func WriteOptionBool(writer Write, value container.OptionOf[bool]) {
+	WriteOption(writer, value, Write.WriteBool)
+}
+
+func ReadOptionI8(reader Read) container.OptionOf[int8] {
+	return ReadOption(reader, Read.ReadI8)
+}
+
+func WriteOptionI8(writer Write, value container.OptionOf[int8]) {
+	WriteOption(writer, value, Write.WriteI8)
+}
+
+func ReadOptionI16(reader Read) container.OptionOf[int16] {
+	return ReadOption(reader, Read.ReadI16)
+}
+
+func WriteOptionI16(writer Write, value container.OptionOf[int16]) {
+	WriteOption(writer, value, Write.WriteI16)
+}
+
+func ReadOptionI32(reader Read) container.OptionOf[int32] {
+	return ReadOption(reader, Read.ReadI32)
+}
+
+func WriteOptionI32(writer Write, value container.OptionOf[int32]) {
+	WriteOption(writer, value, Write.WriteI32)
+}
+
+func ReadOptionI64(reader Read) container.OptionOf[int64] {
+	return ReadOption(reader, Read.ReadI64)
+}
+
+func WriteOptionI64(writer Write, value container.OptionOf[int64]) {
+	WriteOption(writer, value, Write.WriteI64)
+}
+
+func ReadOptionU8(reader Read) container.OptionOf[uint8] {
+	return ReadOption(reader, Read.ReadU8)
+}
+
+func WriteOptionU8(writer Write, value container.OptionOf[uint8]) {
+	WriteOption(writer, value, Write.WriteU8)
+}
+
+func ReadOptionU16(reader Read) container.OptionOf[uint16] {
+	return ReadOption(reader, Read.ReadU16)
+}
+
+func WriteOptionU16(writer Write, value container.OptionOf[uint16]) {
+	WriteOption(writer, value, Write.WriteU16)
+}
+
+func ReadOptionU32(reader Read) container.OptionOf[uint32] {
+	return ReadOption(reader, Read.ReadU32)
+}
+
+func WriteOptionU32(writer Write, value container.OptionOf[uint32]) {
+	WriteOption(writer, value, Write.WriteU32)
+}
+
+func ReadOptionU64(reader Read) container.OptionOf[uint64] {
+	return ReadOption(reader, Read.ReadU64)
+}
+
+func WriteOptionU64(writer Write, value container.OptionOf[uint64]) {
+	WriteOption(writer, value, Write.WriteU64)
+}
+
+func ReadOptionF32(reader Read) container.OptionOf[float32] {
+	return ReadOption(reader, Read.ReadF32)
+}
+
+func WriteOptionFloat32(writer Write, value container.OptionOf[float32]) {
+	WriteOption(writer, value, Write.WriteFloat32)
+}
+
+func ReadOptionF64(reader Read) container.OptionOf[float64] {
+	return ReadOption(reader, Read.ReadF64)
+}
+
+func WriteOptionFloat64(writer Write, value container.OptionOf[float64]) {
+	WriteOption(writer, value, Write.WriteFloat64)
+}
+
+func ReadOptionBytes(reader Read) container.OptionOf[[]byte] {
+	return ReadOption(reader, Read.ReadBytes)
+}
+
+func WriteOptionBytes(writer Write, value container.OptionOf[[]byte]) {
+	WriteOption(writer, value, Write.WriteBytes)
+}
+
+func ReadOptionString(reader Read) container.OptionOf[string] {
+	return ReadOption(reader, Read.ReadString)
+}
+
+func WriteOptionString(writer Write, value container.OptionOf[string]) {
+	WriteOption(writer, value, Write.WriteString)
+}
+
+func ReadOptionBigInt(reader Read) container.OptionOf[*big.Int] {
+	return ReadOption(reader, Read.ReadBigInt)
+}
+
+func WriteOptionBigInt(writer Write, value container.OptionOf[*big.Int]) {
+	WriteOption(writer, value, Write.WriteBigInt)
+}
+
+func ReadOptionBigNumber(reader Read) container.OptionOf[*big.Number] {
+	return ReadOption(reader, Read.ReadBigNumber)
+}
+
+func WriteOptionBigNumber(writer Write, value container.OptionOf[*big.Number]) {
+	WriteOption(writer, value, Write.WriteBigNumber)
+}
+
+func ReadOptionJson(reader Read) container.OptionOf[*fastjson.Value] {
+	return ReadOption(reader, Read.ReadJson)
+}
+
+func WriteOptionJson(writer Write, value container.OptionOf[*fastjson.Value]) {
+	WriteOption(writer, value, Write.WriteJson)
+}
@@ -0,0 +1,130 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+)
+
+func TestReadWriteOption(t *testing.T) {
+	cases := []struct {
+		name    string
+		write   func(writer Write)
+		untyped func(writer Write)
+		read    func(reader Read) interface{}
+		value   interface{}
+	}{
+		{
+			name:    "none bool",
+			write:   func(w Write) { WriteOptionBool(w, container.NoneOf[bool]()) },
+			untyped: func(w Write) { w.WriteOptionalBool(container.None()) },
+			read:    func(r Read) interface{} { return ReadOptionBool(r) },
+			value:   container.NoneOf[bool](),
+		},
+		{
+			name:    "some bool",
+			write:   func(w Write) { WriteOptionBool(w, container.SomeOf(true)) },
+			untyped: func(w Write) { w.WriteOptionalBool(container.Some(true)) },
+			read:    func(r Read) interface{} { return ReadOptionBool(r) },
+			value:   container.SomeOf(true),
+		},
+		{
+			name:    "some int32",
+			write:   func(w Write) { WriteOptionI32(w, container.SomeOf(int32(-1000))) },
+			untyped: func(w Write) { w.WriteOptionalI32(container.Some(int32(-1000))) },
+			read:    func(r Read) interface{} { return ReadOptionI32(r) },
+			value:   container.SomeOf(int32(-1000)),
+		},
+		{
+			name:    "some uint64",
+			write:   func(w Write) { WriteOptionU64(w, container.SomeOf(uint64(1<<40))) },
+			untyped: func(w Write) { w.WriteOptionalU64(container.Some(uint64(1 << 40))) },
+			read:    func(r Read) interface{} { return ReadOptionU64(r) },
+			value:   container.SomeOf(uint64(1 << 40)),
+		},
+		{
+			name:    "some float32",
+			write:   func(w Write) { WriteOptionFloat32(w, container.SomeOf(float32(0.5))) },
+			untyped: func(w Write) { w.WriteOptionalFloat32(container.Some(float32(0.5))) },
+			read:    func(r Read) interface{} { return ReadOptionF32(r) },
+			value:   container.SomeOf(float32(0.5)),
+		},
+		{
+			name:    "none string",
+			write:   func(w Write) { WriteOptionString(w, container.NoneOf[string]()) },
+			untyped: func(w Write) { w.WriteOptionalString(container.None()) },
+			read:    func(r Read) interface{} { return ReadOptionString(r) },
+			value:   container.NoneOf[string](),
+		},
+		{
+			name:    "some string",
+			write:   func(w Write) { WriteOptionString(w, container.SomeOf("value")) },
+			untyped: func(w Write) { w.WriteOptionalString(container.Some("value")) },
+			read:    func(r Read) interface{} { return ReadOptionString(r) },
+			value:   container.SomeOf("value"),
+		},
+		{
+			name:    "some bytes",
+			write:   func(w Write) { WriteOptionBytes(w, container.SomeOf([]byte{1, 2})) },
+			untyped: func(w Write) { w.WriteOptionalBytes(container.Some([]byte{1, 2})) },
+			read:    func(r Read) interface{} { return ReadOptionBytes(r) },
+			value:   container.SomeOf([]byte{1, 2}),
+		},
+		{
+			name:    "some bigint",
+			write:   func(w Write) { WriteOptionBigInt(w, container.SomeOf(big.NewInt(42))) },
+			untyped: func(w Write) { w.WriteOptionalBigInt(container.Some(big.NewInt(42))) },
+			read:    func(r Read) interface{} { return ReadOptionBigInt(r) },
+			value:   container.SomeOf(big.NewInt(42)),
+		},
+		{
+			name: "some array",
+			write: func(w Write) {
+				WriteOption(w, container.SomeOf([]string{"a"}), WriteStringArray)
+			},
+			untyped: func(w Write) {
+				w.WriteOptionalArray(container.Some([]interface{}{"a"}), func(w Write, item interface{}) {
+					w.WriteString(item.(string))
+				})
+			},
+			read:  func(r Read) interface{} { return ReadOption(r, ReadStringArray) },
+			value: container.SomeOf([]string{"a"}),
+		},
+		{
+			name: "array of options",
+			write: func(w Write) {
+				items := []container.OptionOf[int8]{container.SomeOf(int8(1)), container.NoneOf[int8]()}
+				WriteArrayOf(w, items, WriteOptionI8)
+			},
+			untyped: func(w Write) {
+				items := []interface{}{container.Some(int8(1)), container.None()}
+				w.WriteArray(items, func(w Write, item interface{}) {
+					w.WriteOptionalI8(item.(container.Option))
+				})
+			},
+			read:  func(r Read) interface{} { return ReadArrayOf(r, ReadOptionI8) },
+			value: []container.OptionOf[int8]{container.SomeOf(int8(1)), container.NoneOf[int8]()},
+		},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			writer := NewWriteEncoder(NewContext(""))
+			tcase.write(writer)
+			untyped := NewWriteEncoder(NewContext(""))
+			tcase.untyped(untyped)
+			if !bytes.Equal(writer.Buffer(), untyped.Buffer()) {
+				t.Errorf("Bad bytes, got: %x, want: %x", writer.Buffer(), untyped.Buffer())
+			}
+
+			reader := NewReadDecoder(NewContext(""), writer.Buffer())
+			actual := tcase.read(reader)
+			if !reflect.DeepEqual(actual, tcase.value) {
+				t.Errorf("Bad value, got: %#v, want: %#v", actual, tcase.value)
+			}
+		})
+	}
+}
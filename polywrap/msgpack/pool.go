@@ -0,0 +1,70 @@
+package msgpack
+
+import (
+	"sync"
+)
+
+// maxPooledBufferSize bounds the buffers kept by EncoderPool, so that a
+// single large payload does not stay allocated.
+const maxPooledBufferSize = 1 << 20
+
+// EncoderPool reuses WriteEncoders together with their buffer and context
+// stack. The zero value is ready to use and creates default encoders.
+type EncoderPool struct {
+	pool    sync.Pool
+	options WriteOptions
+}
+
+// NewEncoderPool returns a pool creating encoders with the given options.
+func NewEncoderPool(options WriteOptions) *EncoderPool {
+	return &EncoderPool{options: options}
+}
+
+// Get returns an empty encoder whose context has the given description.
+func (p *EncoderPool) Get(description string) *WriteEncoder {
+	if v := p.pool.Get(); v != nil {
+		encoder := v.(*WriteEncoder)
+		encoder.context.Reset(description)
+		return encoder
+	}
+	return NewWriteEncoderWithOptions(NewContext(description), p.options)
+}
+
+// Put returns encoder to the pool. Its Buffer must not be used afterwards.
+func (p *EncoderPool) Put(encoder *WriteEncoder) {
+	if cap(encoder.view.buf) > maxPooledBufferSize {
+		return
+	}
+	encoder.Reset()
+	p.pool.Put(encoder)
+}
+
+// DecoderPool reuses ReadDecoders together with their context stack. The
+// zero value is ready to use and creates decoders with the zero ReadOptions,
+// which record errors instead of panicking.
+type DecoderPool struct {
+	pool    sync.Pool
+	options ReadOptions
+}
+
+// NewDecoderPool returns a pool creating decoders with the given options.
+func NewDecoderPool(options ReadOptions) *DecoderPool {
+	return &DecoderPool{options: options}
+}
+
+// Get returns a decoder reading data whose context has the given description.
+func (p *DecoderPool) Get(description string, data []byte) *ReadDecoder {
+	if v := p.pool.Get(); v != nil {
+		decoder := v.(*ReadDecoder)
+		decoder.Reset(data)
+		decoder.context.Reset(description)
+		return decoder
+	}
+	return NewReadDecoderWithOptions(NewContext(description), data, p.options)
+}
+
+// Put returns decoder to the pool. It drops its reference to the input.
+func (p *DecoderPool) Put(decoder *ReadDecoder) {
+	decoder.Reset(nil)
+	p.pool.Put(decoder)
+}
@@ -0,0 +1,98 @@
+package msgpack
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteEncoderReset(t *testing.T) {
+	encoder := NewWriteEncoder(NewContext("Serializing"))
+	encoder.Context().Push("prop", "string", "writing property")
+	encoder.WriteString("first")
+
+	encoder.Reset()
+	if !encoder.Context().IsEmpty() || len(encoder.Buffer()) != 0 {
+		t.Fatalf("Encoder was not reset: %v, %v", encoder.Context().Length(), encoder.Buffer())
+	}
+	encoder.WriteU8(1)
+	if !bytes.Equal(encoder.Buffer(), []byte{0x01}) {
+		t.Errorf("Bad value, got: %v", encoder.Buffer())
+	}
+}
+
+func TestReadDecoderReset(t *testing.T) {
+	decoder := NewReadDecoderWithOptions(NewContext("Deserializing"), []byte{0xc0}, ReadOptions{})
+	decoder.Context().Push("prop", "bool", "reading property")
+	decoder.ReadBool()
+	if decoder.Err() == nil {
+		t.Fatalf("Expected error")
+	}
+
+	decoder.Reset([]byte{0xc3, 0x05})
+	if decoder.Err() != nil || !decoder.Context().IsEmpty() {
+		t.Fatalf("Decoder was not reset: %v", decoder.Err())
+	}
+	if v := decoder.ReadBool(); !v {
+		t.Errorf("Bad bool, got: %v", v)
+	}
+	if v := decoder.ReadU8(); v != 5 || decoder.Err() != nil {
+		t.Errorf("Bad uint8, got: %v, %v", v, decoder.Err())
+	}
+}
+
+func TestEncoderPool(t *testing.T) {
+	var pool EncoderPool
+	encoder := pool.Get("first")
+	encoder.WriteString("value")
+	pool.Put(encoder)
+
+	encoder = pool.Get("second")
+	defer pool.Put(encoder)
+	if len(encoder.Buffer()) != 0 || encoder.Context().description != "second" {
+		t.Errorf("Pooled encoder was not reset")
+	}
+
+	canonical := NewEncoderPool(WriteOptions{Canonical: true}).Get("canonical")
+	canonical.WriteI64(200)
+	if !bytes.Equal(canonical.Buffer(), []byte{0xcc, 0xc8}) {
+		t.Errorf("Options were not applied, got: %x", canonical.Buffer())
+	}
+}
+
+func TestDecoderPool(t *testing.T) {
+	pool := NewDecoderPool(ReadOptions{Strict: true})
+	decoder := pool.Get("first", []byte{0xc0})
+	decoder.ReadString()
+	if decoder.Err() == nil {
+		t.Fatalf("Expected strict error")
+	}
+	pool.Put(decoder)
+
+	decoder = pool.Get("second", []byte{0xa1, 'a'})
+	defer pool.Put(decoder)
+	if v := decoder.ReadString(); v != "a" || decoder.Err() != nil {
+		t.Errorf("Bad value, got: %v, %v", v, decoder.Err())
+	}
+	if decoder.Context().description != "second" {
+		t.Errorf("Bad description, got: %v", decoder.Context().description)
+	}
+}
+
+func BenchmarkEncoderPool(b *testing.B) {
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encoder := NewWriteEncoder(NewContext("Serializing"))
+			encoder.WriteString(dataViewSample)
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		var pool EncoderPool
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encoder := pool.Get("Serializing")
+			encoder.WriteString(dataViewSample)
+			pool.Put(encoder)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 type Read interface {
 	Context() *Context
+	Err() error
 	IsNil() bool
 
 	ReadBool() bool
@@ -45,10 +46,12 @@ type Read interface {
 
 	ReadBytesLength() uint32
 	ReadBytes() []byte
+	ReadBytesView() []byte
 	ReadOptionalBytes() container.Option
 
 	ReadStringLength() uint32
 	ReadString() string
+	ReadStringView() []byte
 	ReadOptionalString() container.Option
 
 	ReadJson() *fastjson.Value
@@ -57,6 +60,9 @@ type Read interface {
 	ReadBigInt() *big.Int
 	ReadOptionalBigInt() container.Option
 
+	ReadBigNumber() *big.Number
+	ReadOptionalBigNumber() container.Option
+
 	ReadArrayLength() uint32
 	ReadArray(fn func(reader Read) interface{}) []interface{}
 	ReadOptionalArray(fn func(reader Read) interface{}) container.Option
@@ -64,4 +70,11 @@ type Read interface {
 	ReadMapLength() uint32
 	ReadMap(fn func(reader Read) (interface{}, interface{})) map[interface{}]interface{}
 	ReadOptionalMap(fn func(reader Read) (interface{}, interface{})) container.Option
+
+	ReadExt() (int8, []byte)
+	ReadExtGenericMap(fn func(reader Read) (interface{}, interface{})) map[interface{}]interface{}
+	ReadOptionalExtGenericMap(fn func(reader Read) (interface{}, interface{})) container.Option
+
+	ReadValue() (interface{}, error)
+	Skip()
 }
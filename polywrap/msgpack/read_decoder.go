@@ -2,6 +2,8 @@ package msgpack
 
 import (
 	"math"
+	"strconv"
+	"unicode/utf8"
 
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
@@ -9,33 +11,330 @@ import (
 	"github.com/valyala/fastjson"
 )
 
+// ReadOptions configures a ReadDecoder.
+type ReadOptions struct {
+	// PanicOnError makes the decoder panic on the first decoding error, like
+	// NewReadDecoder does. Otherwise the error is recorded and returned by
+	// Err, and every following read returns a zero value: no input makes the
+	// decoder panic, which the fuzz targets in fuzz_test.go check.
+	PanicOnError bool
+	// Strict rejects input that the default, lenient mode coerces: nil where
+	// a non-optional string or map is expected, an array header where a
+	// string is expected and strings that are not valid UTF-8. Finish also
+	// reports trailing bytes after the top-level value. Nil is still read as
+	// empty bytes or an empty array, since WriteEncoder writes them that way.
+	Strict bool
+	// DumpWindow, when positive, adds to decode errors a Dump of the items
+	// starting up to DumpWindow bytes before or after the failing offset.
+	DumpWindow int
+
+	// Limits for untrusted input, zero means unlimited. Lengths are checked
+	// before anything is allocated for them. MaxDepth bounds the nesting of
+	// arrays and maps read by ReadArray, ReadMap, ReadValue, Unmarshal,
+	// ToJSON, Canonicalize and Dump, zero selects DefaultMaxDepth so that
+	// deeply nested input can not overflow the stack. MaxItems bounds the
+	// number of items read, container headers included.
+	MaxDepth        int
+	MaxArrayLength  uint32
+	MaxMapLength    uint32
+	MaxStringLength uint32
+	MaxBytesLength  uint32
+	MaxItems        int
+}
+
+// DefaultMaxDepth is the nesting depth allowed when ReadOptions.MaxDepth is
+// zero.
+const DefaultMaxDepth = 512
+
 type ReadDecoder struct {
 	context *Context
 	view    *DataView
+	options ReadOptions
+	err     *DecodeError
+
+	// format and offset of the last item header read
+	format format.Format
+	offset int
+
+	depth int
+	items int
 }
 
+// NewReadDecoder returns a decoder that panics on malformed input.
 func NewReadDecoder(context *Context, data []byte) *ReadDecoder {
-	return &ReadDecoder{context: context, view: NewDataViewWithBuf(context, data)}
+	return NewReadDecoderWithOptions(context, data, ReadOptions{PanicOnError: true})
+}
+
+func NewReadDecoderWithOptions(context *Context, data []byte, options ReadOptions) *ReadDecoder {
+	return newReadDecoder(context, NewDataViewWithBuf(context, data), options)
+}
+
+func newReadDecoder(context *Context, view *DataView, options ReadOptions) *ReadDecoder {
+	context.bind(view.Offset)
+	return &ReadDecoder{context: context, view: view, options: options}
+}
+
+// Reset makes the decoder read data from the start, clearing its error and
+// context stack.
+func (rd *ReadDecoder) Reset(data []byte) {
+	rd.context.Reset(rd.context.description)
+	rd.view.Reset(data)
+	rd.err = nil
+	rd.format = 0
+	rd.offset = 0
+	rd.depth = 0
+	rd.items = 0
 }
 
 func (rd *ReadDecoder) Context() *Context {
 	return rd.context
 }
 
-func (rd ReadDecoder) IsNil() bool {
-	return rd.view.PeekFormat() == format.NIL
+// Err returns the first error encountered by the decoder, or nil. It is always
+// a *DecodeError.
+func (rd *ReadDecoder) Err() error {
+	if rd.err == nil {
+		return nil
+	}
+	return rd.err
+}
+
+// Finish is called once the top-level value has been read. It returns the
+// first decoding error, and in strict mode an error if unread bytes remain.
+func (rd *ReadDecoder) Finish() error {
+	if rd.options.Strict {
+		rd.checkEnd()
+	}
+	return rd.Err()
+}
+
+// checkEnd fails if unread bytes remain.
+func (rd *ReadDecoder) checkEnd() {
+	if rd.err == nil && rd.view.Fill(1) > 0 {
+		rd.fail(strconv.Itoa(rd.view.Remaining())+" trailing byte(s) after the top-level value",
+			format.ERROR, rd.view.PeekFormat(), rd.view.Offset())
+	}
+}
+
+func (rd *ReadDecoder) fail(message string, expected, found format.Format, offset int) {
+	if rd.err != nil {
+		return
+	}
+	rd.err = &DecodeError{
+		Message:  message,
+		Context:  rd.context.clone(),
+		Expected: expected,
+		Found:    found,
+		Offset:   offset,
+	}
+	if rd.options.DumpWindow > 0 && rd.view.base == 0 {
+		window := rd.options.DumpWindow
+		rd.err.Dump = dump(rd.view.buf, offset-window, offset+window, offset)
+	}
+	if rd.options.PanicOnError {
+		panic(rd.err.Error())
+	}
+}
+
+// unexpected records a mismatch between the expected format and the one of
+// the last item header read.
+func (rd *ReadDecoder) unexpected(message string, expected format.Format) {
+	rd.fail(message, expected, rd.format, rd.offset)
+}
+
+// ensure checks that n more bytes can be read.
+func (rd *ReadDecoder) ensure(n uint32) bool {
+	if rd.err != nil {
+		return false
+	}
+	if uint64(rd.view.Fill(n)) < uint64(n) {
+		if err := rd.view.Err(); err != nil {
+			rd.fail("Read error: "+err.Error(), format.ERROR, format.ERROR, rd.view.Offset())
+			return false
+		}
+		rd.fail("Unexpected end of input: need "+strconv.FormatUint(uint64(n), 10)+" more byte(s), have "+
+			strconv.Itoa(rd.view.Remaining()), format.ERROR, format.ERROR, rd.view.Offset())
+		return false
+	}
+	return true
+}
+
+func (rd *ReadDecoder) readFormat() format.Format {
+	if !rd.ensure(1) {
+		return format.ERROR
+	}
+	rd.offset = rd.view.Offset()
+	rd.format = rd.view.ReadFormat()
+	rd.items++
+	if rd.options.MaxItems > 0 && rd.items > rd.options.MaxItems {
+		rd.unexpected("Input exceeds the limit of "+strconv.Itoa(rd.options.MaxItems)+" items", format.ERROR)
+		return format.ERROR
+	}
+	return rd.format
+}
+
+// checkLength validates the length of a string, binary, extension, array
+// or map before anything is allocated for it. It must fit limit and, when
+// each element takes at least minSize bytes, the remaining input. Streaming
+// input is only checked against limit.
+func (rd *ReadDecoder) checkLength(kind string, size uint32, limit uint32, minSize uint64) uint32 {
+	if rd.err != nil {
+		return 0
+	}
+	if limit > 0 && size > limit {
+		rd.unexpected(kind+" length "+strconv.FormatUint(uint64(size), 10)+" exceeds the limit of "+
+			strconv.FormatUint(uint64(limit), 10), format.ERROR)
+		return 0
+	}
+	if minSize > 0 && rd.view.src == nil && uint64(size)*minSize > uint64(rd.view.Remaining()) {
+		rd.unexpected(kind+" length "+strconv.FormatUint(uint64(size), 10)+" exceeds the remaining input of "+
+			strconv.Itoa(rd.view.Remaining())+" byte(s)", format.ERROR)
+		return 0
+	}
+	if rd.options.MaxItems > 0 && minSize > 0 && uint64(rd.items)+uint64(size)*minSize > uint64(rd.options.MaxItems) {
+		rd.unexpected(kind+" length "+strconv.FormatUint(uint64(size), 10)+" exceeds the limit of "+
+			strconv.Itoa(rd.options.MaxItems)+" items", format.ERROR)
+		return 0
+	}
+	return size
+}
+
+// capacity bounds the preallocation for size elements by the buffered input,
+// as every element takes at least one byte.
+func (rd *ReadDecoder) capacity(size uint32) int {
+	if remaining := rd.view.Remaining(); uint64(size) > uint64(remaining) {
+		return remaining
+	}
+	return int(size)
+}
+
+// enter is called before reading the elements of an array or a map and
+// checks the nesting depth. Every successful enter is paired with a leave.
+func (rd *ReadDecoder) enter() bool {
+	if rd.err != nil {
+		return false
+	}
+	maxDepth := rd.options.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if rd.depth >= maxDepth {
+		rd.unexpected("Input exceeds the maximum nesting depth of "+strconv.Itoa(maxDepth), format.ERROR)
+		return false
+	}
+	rd.depth++
+	return true
+}
+
+func (rd *ReadDecoder) leave() {
+	rd.depth--
+}
+
+func (rd *ReadDecoder) readUint8() uint8 {
+	if !rd.ensure(1) {
+		return 0
+	}
+	return rd.view.ReadUint8()
+}
+
+func (rd *ReadDecoder) readUint16() uint16 {
+	if !rd.ensure(2) {
+		return 0
+	}
+	return rd.view.ReadUint16()
+}
+
+func (rd *ReadDecoder) readUint32() uint32 {
+	if !rd.ensure(4) {
+		return 0
+	}
+	return rd.view.ReadUint32()
+}
+
+func (rd *ReadDecoder) readUint64() uint64 {
+	if !rd.ensure(8) {
+		return 0
+	}
+	return rd.view.ReadUint64()
+}
+
+func (rd *ReadDecoder) readInt8() int8 {
+	if !rd.ensure(1) {
+		return 0
+	}
+	return rd.view.ReadInt8()
+}
+
+func (rd *ReadDecoder) readInt16() int16 {
+	if !rd.ensure(2) {
+		return 0
+	}
+	return rd.view.ReadInt16()
+}
+
+func (rd *ReadDecoder) readInt32() int32 {
+	if !rd.ensure(4) {
+		return 0
+	}
+	return rd.view.ReadInt32()
+}
+
+func (rd *ReadDecoder) readInt64() int64 {
+	if !rd.ensure(8) {
+		return 0
+	}
+	return rd.view.ReadInt64()
+}
+
+func (rd *ReadDecoder) readBytes(ln uint32) []byte {
+	if !rd.ensure(ln) {
+		return nil
+	}
+	return rd.view.ReadBytes(ln)
+}
+
+func (rd *ReadDecoder) readBytesView(ln uint32) []byte {
+	if !rd.ensure(ln) {
+		return nil
+	}
+	return rd.view.ReadBytesView(ln)
+}
+
+// IsNil reports whether the next item is nil without consuming it.
+func (rd *ReadDecoder) IsNil() bool {
+	return rd.peekFormat() == format.NIL
+}
+
+// readNil consumes the next item if it is nil.
+func (rd *ReadDecoder) readNil() bool {
+	if rd.IsNil() {
+		rd.readFormat()
+		return true
+	}
+	return false
+}
+
+func (rd *ReadDecoder) peekFormat() format.Format {
+	if rd.err != nil || rd.view.Fill(1) == 0 {
+		return format.ERROR
+	}
+	return rd.view.PeekFormat()
 }
 
 func (rd *ReadDecoder) ReadBool() bool {
-	f := rd.view.ReadFormat()
+	f := rd.readFormat()
+	if rd.err != nil {
+		return false
+	}
 	if f != format.TRUE && f != format.FALSE {
-		panic(rd.context.PrintWithContext("Property must be of type 'bool'. Found " + format.ToString(f)))
+		rd.unexpected("Property must be of type 'bool'. Found "+format.ToString(f), format.TRUE)
+		return false
 	}
 	return f == format.TRUE
 }
 
 func (rd *ReadDecoder) ReadOptionalBool() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadBool())
@@ -44,13 +343,14 @@ func (rd *ReadDecoder) ReadOptionalBool() container.Option {
 func (rd *ReadDecoder) ReadI8() int8 {
 	v := rd.ReadI64()
 	if math.MinInt8 > v || v > math.MaxInt8 {
-		panic(rd.context.PrintWithContext("int8 overflow"))
+		rd.unexpected("int8 overflow", format.INT8)
+		return 0
 	}
 	return int8(v)
 }
 
 func (rd *ReadDecoder) ReadOptionalI8() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadI8())
@@ -59,13 +359,14 @@ func (rd *ReadDecoder) ReadOptionalI8() container.Option {
 func (rd *ReadDecoder) ReadI16() int16 {
 	v := rd.ReadI64()
 	if math.MinInt16 > v || v > math.MaxInt16 {
-		panic(rd.context.PrintWithContext("int16 overflow"))
+		rd.unexpected("int16 overflow", format.INT16)
+		return 0
 	}
 	return int16(v)
 }
 
 func (rd *ReadDecoder) ReadOptionalI16() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadI16())
@@ -74,48 +375,60 @@ func (rd *ReadDecoder) ReadOptionalI16() container.Option {
 func (rd *ReadDecoder) ReadI32() int32 {
 	v := rd.ReadI64()
 	if math.MinInt32 > v || v > math.MaxInt32 {
-		panic(rd.context.PrintWithContext("int32 overflow"))
+		rd.unexpected("int32 overflow", format.INT32)
+		return 0
 	}
 	return int32(v)
 }
 
 func (rd *ReadDecoder) ReadOptionalI32() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadI32())
 }
 
 func (rd *ReadDecoder) ReadI64() int64 {
-	f := rd.view.ReadFormat()
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	if isFixedInt(uint8(f)) {
 		return int64(f)
 	}
 	if isNegativeFixedInt(uint8(f)) {
-		return int64(f)
+		return int64(int8(f))
 	}
 	switch f {
 	case format.INT8:
-		return int64(rd.view.ReadInt8())
+		return int64(rd.readInt8())
 	case format.INT16:
-		return int64(rd.view.ReadInt16())
+		return int64(rd.readInt16())
 	case format.INT32:
-		return int64(rd.view.ReadInt32())
+		return int64(rd.readInt32())
 	case format.INT64:
-		return rd.view.ReadInt64()
+		return rd.readInt64()
 	case format.UINT8:
-		return int64(rd.view.ReadUint8())
+		return int64(rd.readUint8())
 	case format.UINT16:
-		return int64(rd.view.ReadUint16())
+		return int64(rd.readUint16())
 	case format.UINT32:
-		return int64(rd.view.ReadUint32())
+		return int64(rd.readUint32())
+	case format.UINT64:
+		v := rd.readUint64()
+		if v > math.MaxInt64 {
+			rd.unexpected("int64 overflow", format.INT64)
+			return 0
+		}
+		return int64(v)
 	default:
-		panic(rd.context.PrintWithContext("Property must be of type 'int'. Found " + format.ToString(f)))
+		rd.unexpected("Property must be of type 'int'. Found "+format.ToString(f), format.INT64)
+		return 0
 	}
 }
 
 func (rd *ReadDecoder) ReadOptionalI64() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadI64())
@@ -123,14 +436,15 @@ func (rd *ReadDecoder) ReadOptionalI64() container.Option {
 
 func (rd *ReadDecoder) ReadU8() uint8 {
 	v := rd.ReadU64()
-	if 0 > v || v > math.MaxUint8 {
-		panic(rd.context.PrintWithContext("uint8 overflow"))
+	if v > math.MaxUint8 {
+		rd.unexpected("uint8 overflow", format.UINT8)
+		return 0
 	}
 	return uint8(v)
 }
 
 func (rd *ReadDecoder) ReadOptionalU8() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadU8())
@@ -138,14 +452,15 @@ func (rd *ReadDecoder) ReadOptionalU8() container.Option {
 
 func (rd *ReadDecoder) ReadU16() uint16 {
 	v := rd.ReadU64()
-	if 0 > v || v > math.MaxUint16 {
-		panic(rd.context.PrintWithContext("uint16 overflow"))
+	if v > math.MaxUint16 {
+		rd.unexpected("uint16 overflow", format.UINT16)
+		return 0
 	}
 	return uint16(v)
 }
 
 func (rd *ReadDecoder) ReadOptionalU16() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadU16())
@@ -153,138 +468,206 @@ func (rd *ReadDecoder) ReadOptionalU16() container.Option {
 
 func (rd *ReadDecoder) ReadU32() uint32 {
 	v := rd.ReadU64()
-	if 0 > v || v > math.MaxUint32 {
-		panic(rd.context.PrintWithContext("uint32 overflow"))
+	if v > math.MaxUint32 {
+		rd.unexpected("uint32 overflow", format.UINT32)
+		return 0
 	}
 	return uint32(v)
 }
 
 func (rd *ReadDecoder) ReadOptionalU32() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadU32())
 }
 
 func (rd *ReadDecoder) ReadU64() uint64 {
-	f := rd.view.ReadFormat()
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	if isFixedInt(uint8(f)) {
 		return uint64(f)
 	}
 	if isNegativeFixedInt(uint8(f)) {
-		panic(rd.context.PrintWithContext("Unsigned integer cannot be negative. Found " + format.ToString(f)))
+		rd.unexpected("Unsigned integer cannot be negative. Found "+format.ToString(f), format.UINT64)
+		return 0
 	}
 	switch f {
 	case format.UINT8:
-		return uint64(rd.view.ReadUint8())
+		return uint64(rd.readUint8())
 	case format.UINT16:
-		return uint64(rd.view.ReadUint16())
+		return uint64(rd.readUint16())
 	case format.UINT32:
-		return uint64(rd.view.ReadUint32())
+		return uint64(rd.readUint32())
 	case format.UINT64:
-		return rd.view.ReadUint64()
+		return rd.readUint64()
 	default:
-		panic(rd.context.PrintWithContext("Property must be of type 'uint'. Found " + format.ToString(f)))
+		rd.unexpected("Property must be of type 'uint'. Found "+format.ToString(f), format.UINT64)
+		return 0
 	}
 }
 
 func (rd *ReadDecoder) ReadOptionalU64() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadU64())
 }
 
 func (rd *ReadDecoder) ReadF32() float32 {
-	f := rd.view.ReadFormat()
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	if f != format.FLOAT32 {
-		panic(rd.context.PrintWithContext("Property must be of type 'float32'. Found " + format.ToString(f)))
+		rd.unexpected("Property must be of type 'float32'. Found "+format.ToString(f), format.FLOAT32)
+		return 0
+	}
+	if !rd.ensure(4) {
+		return 0
 	}
 	return rd.view.ReadFloat32()
 }
 
 func (rd *ReadDecoder) ReadOptionalF32() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadF32())
 }
 
 func (rd *ReadDecoder) ReadF64() float64 {
-	f := rd.view.ReadFormat()
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	if f != format.FLOAT64 {
-		panic(rd.context.PrintWithContext("Property must be of type 'float64'. Found " + format.ToString(f)))
+		rd.unexpected("Property must be of type 'float64'. Found "+format.ToString(f), format.FLOAT64)
+		return 0
+	}
+	if !rd.ensure(8) {
+		return 0
 	}
 	return rd.view.ReadFloat64()
 }
 
 func (rd *ReadDecoder) ReadOptionalF64() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadF64())
 }
 
 func (rd *ReadDecoder) ReadBytesLength() uint32 {
-	f := rd.view.ReadFormat()
+	return rd.checkLength("Binary", rd.readBytesHeader(), rd.options.MaxBytesLength, 0)
+}
+
+func (rd *ReadDecoder) readBytesHeader() uint32 {
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	switch f {
 	case format.NIL:
+		// empty bytes are written as nil
 		return 0
 	case format.BIN8:
-		return uint32(rd.view.ReadUint8())
+		return uint32(rd.readUint8())
 	case format.BIN16:
-		return uint32(rd.view.ReadUint16())
+		return uint32(rd.readUint16())
 	case format.BIN32:
-		return rd.view.ReadUint32()
+		return rd.readUint32()
 	}
-	panic(rd.context.PrintWithContext("Property must be of type 'binary'. Found " + format.ToString(f)))
+	rd.unexpected("Property must be of type 'binary'. Found "+format.ToString(f), format.BIN32)
+	return 0
 }
 
 func (rd *ReadDecoder) ReadBytes() []byte {
-	if rd.IsNil() {
+	if rd.readNil() {
+		return nil
+	}
+	ln := rd.ReadBytesLength()
+	if rd.err != nil {
+		return nil
+	}
+	return rd.readBytes(ln)
+}
+
+// ReadBytesView is ReadBytes without the copy: the returned slice aliases the
+// decoder input, so it must not be modified and is only valid as long as the
+// input is. For a StreamDecoder it is only valid until the next read.
+func (rd *ReadDecoder) ReadBytesView() []byte {
+	if rd.readNil() {
+		return nil
+	}
+	ln := rd.ReadBytesLength()
+	if rd.err != nil {
 		return nil
 	}
-	return rd.view.ReadBytes(rd.ReadBytesLength())
+	return rd.readBytesView(ln)
 }
 
 func (rd *ReadDecoder) ReadOptionalBytes() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadBytes())
 }
 
 func (rd *ReadDecoder) ReadStringLength() uint32 {
-	f := rd.view.ReadFormat()
+	return rd.checkLength("String", rd.readStringHeader(), rd.options.MaxStringLength, 0)
+}
+
+func (rd *ReadDecoder) readStringHeader() uint32 {
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	if isFixedString(uint8(f)) {
 		return uint32(uint8(f) & 0x1f)
 	}
-	if isFixedArray(uint8(f)) {
+	if isFixedArray(uint8(f)) && !rd.options.Strict {
 		return uint32(f & format.FOUR_LEAST_SIG_BITS_IN_BYTE)
 	}
 	switch f {
 	case format.NIL:
-		return 0
+		if !rd.options.Strict {
+			return 0
+		}
 	case format.STR8:
-		return uint32(rd.view.ReadUint8())
+		return uint32(rd.readUint8())
 	case format.STR16:
-		return uint32(rd.view.ReadUint16())
+		return uint32(rd.readUint16())
 	case format.STR32:
-		return rd.view.ReadUint32()
+		return rd.readUint32()
 	}
-	panic(rd.context.PrintWithContext("Property must be of type 'string'. Found " + format.ToString(f)))
+	rd.unexpected("Property must be of type 'string'. Found "+format.ToString(f), format.STR32)
+	return 0
 }
 
 func (rd *ReadDecoder) ReadString() string {
+	return string(rd.ReadStringView())
+}
+
+// ReadStringView returns the bytes of the next string without copying them,
+// with the same restrictions as ReadBytesView.
+func (rd *ReadDecoder) ReadStringView() []byte {
 	ln := rd.ReadStringLength()
-	if ln == 0 {
-		return ""
+	if ln == 0 || rd.err != nil {
+		return nil
+	}
+	data := rd.readBytesView(ln)
+	if rd.options.Strict && !utf8.Valid(data) {
+		rd.unexpected("Property must be a valid UTF-8 string", format.STR32)
+		return nil
 	}
-	return string(rd.view.ReadBytes(ln))
+	return data
 }
 
 func (rd *ReadDecoder) ReadOptionalString() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadString())
@@ -295,11 +678,16 @@ func (rd *ReadDecoder) ReadJson() *fastjson.Value {
 	if tmp == "" {
 		return nil
 	}
-	return fastjson.MustParse(tmp)
+	val, err := fastjson.Parse(tmp)
+	if err != nil {
+		rd.unexpected("Property must be of type 'JSON': "+err.Error(), format.STR32)
+		return nil
+	}
+	return val
 }
 
 func (rd *ReadDecoder) ReadOptionalJson() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadJson())
@@ -312,21 +700,50 @@ func (rd *ReadDecoder) ReadBigInt() *big.Int {
 	}
 	val, ok := new(big.Int).SetString(tmp, 10)
 	if !ok {
-		panic(rd.context.PrintWithContext("Property must be of type 'BigInt'"))
+		rd.unexpected("Property must be of type 'BigInt'", format.STR32)
+		return nil
 	}
 	return val
 }
 
 func (rd *ReadDecoder) ReadOptionalBigInt() container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadBigInt())
 }
 
+func (rd *ReadDecoder) ReadBigNumber() *big.Number {
+	tmp := rd.ReadString()
+	if tmp == "" {
+		return nil
+	}
+	val, ok := new(big.Number).SetString(tmp)
+	if !ok {
+		rd.unexpected("Property must be of type 'BigNumber'", format.STR32)
+		return nil
+	}
+	return val
+}
+
+func (rd *ReadDecoder) ReadOptionalBigNumber() container.Option {
+	if rd.readNil() {
+		return container.None()
+	}
+	return container.Some(rd.ReadBigNumber())
+}
+
 func (rd *ReadDecoder) ReadArrayLength() uint32 {
-	f := rd.view.ReadFormat()
+	return rd.checkLength("Array", rd.readArrayHeader(), rd.options.MaxArrayLength, 1)
+}
+
+func (rd *ReadDecoder) readArrayHeader() uint32 {
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
 	if f == format.NIL {
+		// empty arrays are written as nil
 		return 0
 	}
 	if isFixedArray(uint8(f)) {
@@ -334,34 +751,46 @@ func (rd *ReadDecoder) ReadArrayLength() uint32 {
 	}
 	switch f {
 	case format.ARRAY16:
-		return uint32(rd.view.ReadUint16())
+		return uint32(rd.readUint16())
 	case format.ARRAY32:
-		return rd.view.ReadUint32()
-	case format.NIL:
-		return 0
+		return rd.readUint32()
 	}
-	panic(rd.context.PrintWithContext("Property must be of type 'array'. Found " + format.ToString(f)))
+	rd.unexpected("Property must be of type 'array'. Found "+format.ToString(f), format.ARRAY32)
+	return 0
 }
 
 func (rd *ReadDecoder) ReadArray(fn func(reader Read) interface{}) []interface{} {
 	size := rd.ReadArrayLength()
-	data := make([]interface{}, size)
-	for i := uint32(0); i < size; i++ {
-		data[i] = fn(rd)
+	data := make([]interface{}, 0, rd.capacity(size))
+	if !rd.enter() {
+		return data
+	}
+	defer rd.leave()
+	for i := uint32(0); i < size && rd.err == nil; i++ {
+		rd.context.Push(strconv.FormatUint(uint64(i), 10), "array item", "reading array item")
+		data = append(data, fn(rd))
+		rd.context.Pop()
 	}
 	return data
 }
 
 func (rd *ReadDecoder) ReadOptionalArray(fn func(reader Read) interface{}) container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadArray(fn))
 }
 
 func (rd *ReadDecoder) ReadMapLength() uint32 {
-	f := rd.view.ReadFormat()
-	if f == format.NIL {
+	return rd.checkLength("Map", rd.readMapHeader(), rd.options.MaxMapLength, 2)
+}
+
+func (rd *ReadDecoder) readMapHeader() uint32 {
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
+	if f == format.NIL && !rd.options.Strict {
 		return 0
 	}
 	if isFixedMap(uint8(f)) {
@@ -369,19 +798,22 @@ func (rd *ReadDecoder) ReadMapLength() uint32 {
 	}
 	switch f {
 	case format.MAP16:
-		return uint32(rd.view.ReadUint16())
+		return uint32(rd.readUint16())
 	case format.MAP32:
-		return rd.view.ReadUint32()
-	case format.NIL:
-		return 0
+		return rd.readUint32()
 	}
-	panic(rd.context.PrintWithContext("Property must be of type 'map'. Found " + format.ToString(f)))
+	rd.unexpected("Property must be of type 'map'. Found "+format.ToString(f), format.MAP32)
+	return 0
 }
 
 func (rd *ReadDecoder) ReadMap(fn func(reader Read) (interface{}, interface{})) map[interface{}]interface{} {
 	size := rd.ReadMapLength()
 	data := make(map[interface{}]interface{})
-	for i := uint32(0); i < size; i++ {
+	if !rd.enter() {
+		return data
+	}
+	defer rd.leave()
+	for i := uint32(0); i < size && rd.err == nil; i++ {
 		k, v := fn(rd)
 		data[k] = v
 	}
@@ -389,12 +821,297 @@ func (rd *ReadDecoder) ReadMap(fn func(reader Read) (interface{}, interface{}))
 }
 
 func (rd *ReadDecoder) ReadOptionalMap(fn func(reader Read) (interface{}, interface{})) container.Option {
-	if rd.IsNil() {
+	if rd.readNil() {
 		return container.None()
 	}
 	return container.Some(rd.ReadMap(fn))
 }
 
+func (rd *ReadDecoder) readExtLength() uint32 {
+	return rd.checkLength("Extension", rd.readExtHeader(), rd.options.MaxBytesLength, 0)
+}
+
+func (rd *ReadDecoder) readExtHeader() uint32 {
+	f := rd.readFormat()
+	if rd.err != nil {
+		return 0
+	}
+	switch f {
+	case format.FIXEXT1:
+		return 1
+	case format.FIXEXT2:
+		return 2
+	case format.FIXEXT4:
+		return 4
+	case format.FIXEXT8:
+		return 8
+	case format.FIXEXT16:
+		return 16
+	case format.EXT8:
+		return uint32(rd.readUint8())
+	case format.EXT16:
+		return uint32(rd.readUint16())
+	case format.EXT32:
+		return rd.readUint32()
+	}
+	rd.unexpected("Property must be of type 'ext'. Found "+format.ToString(f), format.EXT32)
+	return 0
+}
+
+// ReadExt reads an extension value and returns its type ID and payload.
+func (rd *ReadDecoder) ReadExt() (int8, []byte) {
+	ln := rd.readExtLength()
+	typ := rd.readInt8()
+	data := rd.readBytes(ln)
+	if rd.err != nil {
+		return 0, nil
+	}
+	return typ, data
+}
+
+func (rd *ReadDecoder) readExtGenericMapLength() uint32 {
+	ln := rd.readExtLength()
+	typ := rd.readInt8()
+	if rd.err != nil {
+		return 0
+	}
+	if typ != ExtGenericMap {
+		rd.unexpected("Extension must be of type 'GenericMap'. Found type "+strconv.Itoa(int(typ)), format.EXT32)
+		return 0
+	}
+	return ln
+}
+
+// ReadExtGenericMap reads a map wrapped in a Polywrap GenericMap extension.
+func (rd *ReadDecoder) ReadExtGenericMap(fn func(reader Read) (interface{}, interface{})) map[interface{}]interface{} {
+	ln := rd.readExtGenericMapLength()
+	if rd.err != nil {
+		return make(map[interface{}]interface{})
+	}
+	start, f, offset := rd.view.Offset(), rd.format, rd.offset
+	data := rd.ReadMap(fn)
+	if rd.err == nil && rd.view.Offset()-start != int(ln) {
+		rd.fail("GenericMap extension length mismatch: header says "+strconv.FormatUint(uint64(ln), 10)+
+			" byte(s), map has "+strconv.Itoa(rd.view.Offset()-start), format.EXT32, f, offset)
+	}
+	return data
+}
+
+func (rd *ReadDecoder) ReadOptionalExtGenericMap(fn func(reader Read) (interface{}, interface{})) container.Option {
+	if rd.readNil() {
+		return container.None()
+	}
+	return container.Some(rd.ReadExtGenericMap(fn))
+}
+
+// ReadValue decodes the next item of any type into nil, bool, int64 (signed
+// and fixed ints), uint64 (unsigned ints), float64, string, []byte,
+// []interface{} or a map. Maps are returned as map[string]interface{} when
+// all their keys are strings and as map[interface{}]interface{} otherwise.
+// GenericMap extensions are returned as the map they wrap, extensions
+// registered in DefaultExtRegistry as their decoded value and other
+// extensions as Ext.
+func (rd *ReadDecoder) ReadValue() (interface{}, error) {
+	value := rd.readValue()
+	if rd.err != nil {
+		return nil, rd.Err()
+	}
+	return value, nil
+}
+
+func (rd *ReadDecoder) readValue() interface{} {
+	f := rd.peekFormat()
+	if rd.err != nil || rd.view.Fill(1) == 0 {
+		// reports the end of input
+		rd.readFormat()
+		return nil
+	}
+	switch {
+	case f == format.NIL:
+		rd.readFormat()
+		return nil
+	case f == format.TRUE || f == format.FALSE:
+		return rd.ReadBool()
+	case isFixedInt(uint8(f)) || isNegativeFixedInt(uint8(f)),
+		f == format.INT8, f == format.INT16, f == format.INT32, f == format.INT64:
+		return rd.ReadI64()
+	case f == format.UINT8, f == format.UINT16, f == format.UINT32, f == format.UINT64:
+		return rd.ReadU64()
+	case f == format.FLOAT32:
+		return float64(rd.ReadF32())
+	case f == format.FLOAT64:
+		return rd.ReadF64()
+	case isFixedString(uint8(f)), f == format.STR8, f == format.STR16, f == format.STR32:
+		return rd.ReadString()
+	case f == format.BIN8, f == format.BIN16, f == format.BIN32:
+		return rd.ReadBytes()
+	case isFixedArray(uint8(f)), f == format.ARRAY16, f == format.ARRAY32:
+		size := rd.ReadArrayLength()
+		data := make([]interface{}, 0, rd.capacity(size))
+		if !rd.enter() {
+			return nil
+		}
+		defer rd.leave()
+		for i := uint32(0); i < size && rd.err == nil; i++ {
+			data = append(data, rd.readValue())
+		}
+		return data
+	case isFixedMap(uint8(f)), f == format.MAP16, f == format.MAP32:
+		return rd.readMapValue()
+	case isExt(f):
+		return rd.readExtValue()
+	default:
+		rd.readFormat()
+		rd.unexpected("Unknown format "+format.ToString(f), format.ERROR)
+		return nil
+	}
+}
+
+func (rd *ReadDecoder) readMapValue() interface{} {
+	size := rd.ReadMapLength()
+	keys := make([]interface{}, 0, rd.capacity(size))
+	values := make([]interface{}, 0, rd.capacity(size))
+	if !rd.enter() {
+		return nil
+	}
+	defer rd.leave()
+	stringKeys := true
+	for i := uint32(0); i < size && rd.err == nil; i++ {
+		offset := rd.view.Offset()
+		key := rd.readValue()
+		if rd.err != nil {
+			break
+		}
+		if !isHashable(key) {
+			rd.fail("Map keys must be scalar values", format.ERROR, rd.format, offset)
+			break
+		}
+		_, isString := key.(string)
+		stringKeys = stringKeys && isString
+		keys = append(keys, key)
+		values = append(values, rd.readValue())
+	}
+	if rd.err != nil {
+		return nil
+	}
+
+	if stringKeys {
+		data := make(map[string]interface{}, len(keys))
+		for i := range keys {
+			data[keys[i].(string)] = values[i]
+		}
+		return data
+	}
+	data := make(map[interface{}]interface{}, len(keys))
+	for i := range keys {
+		data[keys[i]] = values[i]
+	}
+	return data
+}
+
+func (rd *ReadDecoder) readExtValue() interface{} {
+	offset := rd.view.Offset()
+	ln := rd.readExtLength()
+	typ := rd.readInt8()
+	if rd.err != nil {
+		return nil
+	}
+	if typ == ExtGenericMap {
+		start := rd.view.Offset()
+		value := rd.readMapValue()
+		if rd.err == nil && rd.view.Offset()-start != int(ln) {
+			rd.fail("GenericMap extension length mismatch", format.EXT32, rd.format, offset)
+		}
+		return value
+	}
+
+	data := rd.readBytes(ln)
+	if rd.err != nil {
+		return nil
+	}
+	if entry := DefaultExtRegistry.lookupType(typ); entry != nil {
+		value, err := entry.decoder(data)
+		if err != nil {
+			rd.fail(err.Error(), format.EXT32, rd.format, offset)
+			return nil
+		}
+		if value == nil {
+			rd.fail("Extension type "+strconv.Itoa(int(typ))+" decoded to nil", format.EXT32, rd.format, offset)
+		}
+		return value
+	}
+	return Ext{Type: typ, Data: data}
+}
+
+// Skip consumes the next item, whatever its type, including the content of
+// arrays, maps and extensions.
+func (rd *ReadDecoder) Skip() {
+	for pending := uint64(1); pending > 0 && rd.err == nil; pending-- {
+		f := rd.readFormat()
+		if rd.err != nil {
+			return
+		}
+		switch {
+		case isFixedInt(uint8(f)), isNegativeFixedInt(uint8(f)),
+			f == format.NIL, f == format.TRUE, f == format.FALSE:
+		case isFixedString(uint8(f)):
+			rd.skipBytes(uint32(uint8(f) & 0x1f))
+		case isFixedArray(uint8(f)):
+			pending += uint64(f & format.FOUR_LEAST_SIG_BITS_IN_BYTE)
+		case isFixedMap(uint8(f)):
+			pending += 2 * uint64(f&format.FOUR_LEAST_SIG_BITS_IN_BYTE)
+		case f == format.INT8, f == format.UINT8:
+			rd.skipBytes(1)
+		case f == format.INT16, f == format.UINT16:
+			rd.skipBytes(2)
+		case f == format.INT32, f == format.UINT32, f == format.FLOAT32:
+			rd.skipBytes(4)
+		case f == format.INT64, f == format.UINT64, f == format.FLOAT64:
+			rd.skipBytes(8)
+		case f == format.STR8, f == format.BIN8:
+			rd.skipBytes(uint32(rd.readUint8()))
+		case f == format.STR16, f == format.BIN16:
+			rd.skipBytes(uint32(rd.readUint16()))
+		case f == format.STR32, f == format.BIN32:
+			rd.skipBytes(rd.readUint32())
+		case f == format.ARRAY16:
+			pending += uint64(rd.readUint16())
+		case f == format.ARRAY32:
+			pending += uint64(rd.readUint32())
+		case f == format.MAP16:
+			pending += 2 * uint64(rd.readUint16())
+		case f == format.MAP32:
+			pending += 2 * uint64(rd.readUint32())
+		case f == format.FIXEXT1:
+			rd.skipBytes(1 + 1)
+		case f == format.FIXEXT2:
+			rd.skipBytes(1 + 2)
+		case f == format.FIXEXT4:
+			rd.skipBytes(1 + 4)
+		case f == format.FIXEXT8:
+			rd.skipBytes(1 + 8)
+		case f == format.FIXEXT16:
+			rd.skipBytes(1 + 16)
+		case f == format.EXT8:
+			rd.skipBytes(1 + uint32(rd.readUint8()))
+		case f == format.EXT16:
+			rd.skipBytes(1 + uint32(rd.readUint16()))
+		case f == format.EXT32:
+			ln := rd.readUint32()
+			rd.skipBytes(1)
+			rd.skipBytes(ln)
+		default:
+			rd.unexpected("Unknown format "+format.ToString(f), format.ERROR)
+		}
+	}
+}
+
+func (rd *ReadDecoder) skipBytes(n uint32) {
+	if rd.ensure(n) {
+		rd.view.Skip(n)
+	}
+}
+
 func isFixedInt(v uint8) bool {
 	return v>>7 == 0
 }
@@ -411,6 +1128,15 @@ func isFixedArray(v uint8) bool {
 	return format.Format(v&0xf0) == format.FIXARRAY
 }
 
+func isExt(f format.Format) bool {
+	switch f {
+	case format.FIXEXT1, format.FIXEXT2, format.FIXEXT4, format.FIXEXT8, format.FIXEXT16,
+		format.EXT8, format.EXT16, format.EXT32:
+		return true
+	}
+	return false
+}
+
 func isNegativeFixedInt(v uint8) bool {
 	return format.Format(v&0xe0) == format.NEGATIVE_FIXINT
 }
@@ -3,10 +3,12 @@ package msgpack
 import (
 	"math"
 	"reflect"
+	"runtime"
 	"testing"
 
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
 	"github.com/valyala/fastjson"
 )
 
@@ -94,6 +96,10 @@ func runReadCases(t *testing.T, cases []readcase) {
 				v = reader.ReadBigInt()
 			case "bigint?":
 				v = reader.ReadOptionalBigInt()
+			case "bignumber":
+				v = reader.ReadBigNumber()
+			case "bignumber?":
+				v = reader.ReadOptionalBigNumber()
 			case "json":
 				v = reader.ReadJson()
 			case "json?":
@@ -108,857 +114,1084 @@ func runReadCases(t *testing.T, cases []readcase) {
 	}
 }
 
+var isNilCases = []readcase{
+	{
+		name:   "can read value",
+		bytes:  []byte{192},
+		format: "nil",
+		value:  true,
+	},
+}
+
 func TestIsNil(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read value",
-			bytes:  []byte{192},
-			format: "nil",
-			value:  true,
-		},
-	})
+	runReadCases(t, isNilCases)
+}
+
+var readBoolCases = []readcase{
+	{
+		name:   "can read false",
+		bytes:  []byte{194},
+		format: "bool",
+		value:  false,
+	},
+	{
+		name:   "can read true",
+		bytes:  []byte{195},
+		format: "bool",
+		value:  true,
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "bool?",
+		value:  container.None(),
+	},
+	{
+		name:   "can optional false",
+		bytes:  []byte{194},
+		format: "bool?",
+		value:  container.Some(false),
+	},
+	{
+		name:   "can optional true",
+		bytes:  []byte{195},
+		format: "bool?",
+		value:  container.Some(true),
+	},
 }
 
 func TestReadBool(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read false",
-			bytes:  []byte{194},
-			format: "bool",
-			value:  false,
-		},
-		{
-			name:   "can read true",
-			bytes:  []byte{195},
-			format: "bool",
-			value:  true,
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "bool?",
-			value:  container.None(),
-		},
-		{
-			name:   "can optional false",
-			bytes:  []byte{194},
-			format: "bool?",
-			value:  container.Some(false),
-		},
-		{
-			name:   "can optional true",
-			bytes:  []byte{195},
-			format: "bool?",
-			value:  container.Some(true),
-		},
-	})
+	runReadCases(t, readBoolCases)
+}
+
+var readI8Cases = []readcase{
+	{
+		name:   "can read min i8",
+		bytes:  []byte{0xd0, 0x80},
+		format: "int8",
+		value:  int8(math.MinInt8),
+	},
+	{
+		name:   "can read max i8",
+		bytes:  []byte{0x7f},
+		format: "int8",
+		value:  int8(math.MaxInt8),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "int8?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min i8",
+		bytes:  []byte{0xd0, 0x80},
+		format: "int8?",
+		value:  container.Some(int8(math.MinInt8)),
+	},
+	{
+		name:   "can read optional max i8",
+		bytes:  []byte{0x7f},
+		format: "int8?",
+		value:  container.Some(int8(math.MaxInt8)),
+	},
 }
 
 func TestReadI8(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min i8",
-			bytes:  []byte{0xd0, 0x80},
-			format: "int8",
-			value:  int8(math.MinInt8),
-		},
-		{
-			name:   "can read max i8",
-			bytes:  []byte{0x7f},
-			format: "int8",
-			value:  int8(math.MaxInt8),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "int8?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min i8",
-			bytes:  []byte{0xd0, 0x80},
-			format: "int8?",
-			value:  container.Some(int8(math.MinInt8)),
-		},
-		{
-			name:   "can read optional max i8",
-			bytes:  []byte{0x7f},
-			format: "int8?",
-			value:  container.Some(int8(math.MaxInt8)),
-		},
-	})
+	runReadCases(t, readI8Cases)
+}
+
+var readI16Cases = []readcase{
+	{
+		name:   "can read min i16",
+		bytes:  []byte{0xd1, 0x80, 0x0},
+		format: "int16",
+		value:  int16(math.MinInt16),
+	},
+	{
+		name:   "can read max i16",
+		bytes:  []byte{0xd1, 0x7f, 0xff},
+		format: "int16",
+		value:  int16(math.MaxInt16),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "int16?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min i16",
+		bytes:  []byte{0xd1, 0x80, 0x0},
+		format: "int16?",
+		value:  container.Some(int16(math.MinInt16)),
+	},
+	{
+		name:   "can read optional max i16",
+		bytes:  []byte{0xd1, 0x7f, 0xff},
+		format: "int16?",
+		value:  container.Some(int16(math.MaxInt16)),
+	},
 }
 
 func TestReadI16(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min i16",
-			bytes:  []byte{0xd1, 0x80, 0x0},
-			format: "int16",
-			value:  int16(math.MinInt16),
-		},
-		{
-			name:   "can read max i16",
-			bytes:  []byte{0xd1, 0x7f, 0xff},
-			format: "int16",
-			value:  int16(math.MaxInt16),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "int16?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min i16",
-			bytes:  []byte{0xd1, 0x80, 0x0},
-			format: "int16?",
-			value:  container.Some(int16(math.MinInt16)),
-		},
-		{
-			name:   "can read optional max i16",
-			bytes:  []byte{0xd1, 0x7f, 0xff},
-			format: "int16?",
-			value:  container.Some(int16(math.MaxInt16)),
-		},
-	})
+	runReadCases(t, readI16Cases)
+}
+
+var readI32Cases = []readcase{
+	{
+		name:   "can read min i32",
+		bytes:  []byte{0xd2, 0x80, 0x0, 0x0, 0x0},
+		format: "int32",
+		value:  int32(math.MinInt32),
+	},
+	{
+		name:   "can read max i32",
+		bytes:  []byte{0xd2, 0x7f, 0xff, 0xff, 0xff},
+		format: "int32",
+		value:  int32(math.MaxInt32),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "int32?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min i32",
+		bytes:  []byte{0xd2, 0x80, 0x0, 0x0, 0x0},
+		format: "int32?",
+		value:  container.Some(int32(math.MinInt32)),
+	},
+	{
+		name:   "can read optional max i32",
+		bytes:  []byte{0xd2, 0x7f, 0xff, 0xff, 0xff},
+		format: "int32?",
+		value:  container.Some(int32(math.MaxInt32)),
+	},
 }
 
 func TestReadI32(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min i32",
-			bytes:  []byte{0xd2, 0x80, 0x0, 0x0, 0x0},
-			format: "int32",
-			value:  int32(math.MinInt32),
-		},
-		{
-			name:   "can read max i32",
-			bytes:  []byte{0xd2, 0x7f, 0xff, 0xff, 0xff},
-			format: "int32",
-			value:  int32(math.MaxInt32),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "int32?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min i32",
-			bytes:  []byte{0xd2, 0x80, 0x0, 0x0, 0x0},
-			format: "int32?",
-			value:  container.Some(int32(math.MinInt32)),
-		},
-		{
-			name:   "can read optional max i32",
-			bytes:  []byte{0xd2, 0x7f, 0xff, 0xff, 0xff},
-			format: "int32?",
-			value:  container.Some(int32(math.MaxInt32)),
-		},
-	})
+	runReadCases(t, readI32Cases)
+}
+
+var readI64Cases = []readcase{
+	{
+		name:   "can read min i64",
+		bytes:  []byte{0xd3, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		format: "int64",
+		value:  int64(math.MinInt64),
+	},
+	{
+		name:   "can read max i64",
+		bytes:  []byte{0xd3, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		format: "int64",
+		value:  int64(math.MaxInt64),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "int64?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min i64",
+		bytes:  []byte{0xd3, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		format: "int64?",
+		value:  container.Some(int64(math.MinInt64)),
+	},
+	{
+		name:   "can read optional max i64",
+		bytes:  []byte{0xd3, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		format: "int64?",
+		value:  container.Some(int64(math.MaxInt64)),
+	},
 }
 
 func TestReadI64(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min i64",
-			bytes:  []byte{0xd3, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
-			format: "int64",
-			value:  int64(math.MinInt64),
-		},
-		{
-			name:   "can read max i64",
-			bytes:  []byte{0xd3, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
-			format: "int64",
-			value:  int64(math.MaxInt64),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "int64?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min i64",
-			bytes:  []byte{0xd3, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
-			format: "int64?",
-			value:  container.Some(int64(math.MinInt64)),
-		},
-		{
-			name:   "can read optional max i64",
-			bytes:  []byte{0xd3, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
-			format: "int64?",
-			value:  container.Some(int64(math.MaxInt64)),
-		},
-	})
+	runReadCases(t, readI64Cases)
+}
+
+var readU8Cases = []readcase{
+	{
+		name:   "can read min u8",
+		bytes:  []byte{0x0},
+		format: "uint8",
+		value:  uint8(0),
+	},
+	{
+		name:   "can read max u8",
+		bytes:  []byte{0xcc, 0xff},
+		format: "uint8",
+		value:  uint8(math.MaxUint8),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "uint8?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min u8",
+		bytes:  []byte{0x0},
+		format: "uint8?",
+		value:  container.Some(uint8(0)),
+	},
+	{
+		name:   "can read optional max u8",
+		bytes:  []byte{0xcc, 0xff},
+		format: "uint8?",
+		value:  container.Some(uint8(math.MaxUint8)),
+	},
 }
 
 func TestReadU8(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min u8",
-			bytes:  []byte{0x0},
-			format: "uint8",
-			value:  uint8(0),
-		},
-		{
-			name:   "can read max u8",
-			bytes:  []byte{0xcc, 0xff},
-			format: "uint8",
-			value:  uint8(math.MaxUint8),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "uint8?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min u8",
-			bytes:  []byte{0x0},
-			format: "uint8?",
-			value:  container.Some(uint8(0)),
-		},
-		{
-			name:   "can read optional max u8",
-			bytes:  []byte{0xcc, 0xff},
-			format: "uint8?",
-			value:  container.Some(uint8(math.MaxUint8)),
-		},
-	})
+	runReadCases(t, readU8Cases)
+}
+
+var readU16Cases = []readcase{
+	{
+		name:   "can read min u16",
+		bytes:  []byte{0x0},
+		format: "uint16",
+		value:  uint16(0),
+	},
+	{
+		name:   "can read max u16",
+		bytes:  []byte{0xcd, 0xff, 0xff},
+		format: "uint16",
+		value:  uint16(math.MaxUint16),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "uint16?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min u16",
+		bytes:  []byte{0x0},
+		format: "uint16?",
+		value:  container.Some(uint16(0)),
+	},
+	{
+		name:   "can read optional max u16",
+		bytes:  []byte{0xcd, 0xff, 0xff},
+		format: "uint16?",
+		value:  container.Some(uint16(math.MaxUint16)),
+	},
 }
 
 func TestReadU16(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min u16",
-			bytes:  []byte{0x0},
-			format: "uint16",
-			value:  uint16(0),
-		},
-		{
-			name:   "can read max u16",
-			bytes:  []byte{0xcd, 0xff, 0xff},
-			format: "uint16",
-			value:  uint16(math.MaxUint16),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "uint16?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min u16",
-			bytes:  []byte{0x0},
-			format: "uint16?",
-			value:  container.Some(uint16(0)),
-		},
-		{
-			name:   "can read optional max u16",
-			bytes:  []byte{0xcd, 0xff, 0xff},
-			format: "uint16?",
-			value:  container.Some(uint16(math.MaxUint16)),
-		},
-	})
+	runReadCases(t, readU16Cases)
+}
+
+var readU32Cases = []readcase{
+	{
+		name:   "can read min u32",
+		bytes:  []byte{0x0},
+		format: "uint32",
+		value:  uint32(0),
+	},
+	{
+		name:   "can read max u32",
+		bytes:  []byte{0xce, 0xff, 0xff, 0xff, 0xff},
+		format: "uint32",
+		value:  uint32(math.MaxUint32),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "uint32?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min u32",
+		bytes:  []byte{0x0},
+		format: "uint32?",
+		value:  container.Some(uint32(0)),
+	},
+	{
+		name:   "can read optional max u32",
+		bytes:  []byte{0xce, 0xff, 0xff, 0xff, 0xff},
+		format: "uint32?",
+		value:  container.Some(uint32(math.MaxUint32)),
+	},
 }
 
 func TestReadU32(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min u32",
-			bytes:  []byte{0x0},
-			format: "uint32",
-			value:  uint32(0),
-		},
-		{
-			name:   "can read max u32",
-			bytes:  []byte{0xce, 0xff, 0xff, 0xff, 0xff},
-			format: "uint32",
-			value:  uint32(math.MaxUint32),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "uint32?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min u32",
-			bytes:  []byte{0x0},
-			format: "uint32?",
-			value:  container.Some(uint32(0)),
-		},
-		{
-			name:   "can read optional max u32",
-			bytes:  []byte{0xce, 0xff, 0xff, 0xff, 0xff},
-			format: "uint32?",
-			value:  container.Some(uint32(math.MaxUint32)),
-		},
-	})
+	runReadCases(t, readU32Cases)
+}
+
+var readU64Cases = []readcase{
+	{
+		name:   "can read min u64",
+		bytes:  []byte{0x0},
+		format: "uint64",
+		value:  uint64(0),
+	},
+	{
+		name:   "can read max u64",
+		bytes:  []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		format: "uint64",
+		value:  uint64(math.MaxUint64),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "uint64?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional min u64",
+		bytes:  []byte{0x0},
+		format: "uint64?",
+		value:  container.Some(uint64(0)),
+	},
+	{
+		name:   "can read optional max u64",
+		bytes:  []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		format: "uint64?",
+		value:  container.Some(uint64(math.MaxUint64)),
+	},
 }
 
 func TestReadU64(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read min u64",
-			bytes:  []byte{0x0},
-			format: "uint64",
-			value:  uint64(0),
-		},
-		{
-			name:   "can read max u64",
-			bytes:  []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
-			format: "uint64",
-			value:  uint64(math.MaxUint64),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "uint64?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional min u64",
-			bytes:  []byte{0x0},
-			format: "uint64?",
-			value:  container.Some(uint64(0)),
-		},
-		{
-			name:   "can read optional max u64",
-			bytes:  []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
-			format: "uint64?",
-			value:  container.Some(uint64(math.MaxUint64)),
-		},
-	})
+	runReadCases(t, readU64Cases)
+}
+
+var readF32Cases = []readcase{
+	{
+		name:   "can read negative f32",
+		bytes:  []byte{0xca, 0xbf, 0x0, 0x0, 0x0},
+		format: "float32",
+		value:  float32(-0.5),
+	},
+	{
+		name:   "can read zero f32",
+		bytes:  []byte{0xca, 0x0, 0x0, 0x0, 0x0},
+		format: "float32",
+		value:  float32(0),
+	},
+	{
+		name:   "can read f32",
+		bytes:  []byte{0xca, 0x3d, 0xe3, 0x8e, 0x39},
+		format: "float32",
+		value:  float32(0.1111111111111),
+	},
+	{
+		name:   "can read max f32",
+		bytes:  []byte{0xca, 0x7f, 0x7f, 0xff, 0xff},
+		format: "float32",
+		value:  float32(math.MaxFloat32),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "float32?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional negative f32",
+		bytes:  []byte{0xca, 0xbf, 0x0, 0x0, 0x0},
+		format: "float32?",
+		value:  container.Some(float32(-0.5)),
+	},
+	{
+		name:   "can read optional zero f32",
+		bytes:  []byte{0xca, 0x0, 0x0, 0x0, 0x0},
+		format: "float32?",
+		value:  container.Some(float32(0)),
+	},
+	{
+		name:   "can read optional f32",
+		bytes:  []byte{0xca, 0x3d, 0xe3, 0x8e, 0x39},
+		format: "float32?",
+		value:  container.Some(float32(0.1111111111111)),
+	},
+	{
+		name:   "can read optional max f32",
+		bytes:  []byte{0xca, 0x7f, 0x7f, 0xff, 0xff},
+		format: "float32?",
+		value:  container.Some(float32(math.MaxFloat32)),
+	},
 }
 
 func TestReadF32(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read negative f32",
-			bytes:  []byte{0xca, 0xbf, 0x0, 0x0, 0x0},
-			format: "float32",
-			value:  float32(-0.5),
-		},
-		{
-			name:   "can read zero f32",
-			bytes:  []byte{0xca, 0x0, 0x0, 0x0, 0x0},
-			format: "float32",
-			value:  float32(0),
-		},
-		{
-			name:   "can read f32",
-			bytes:  []byte{0xca, 0x3d, 0xe3, 0x8e, 0x39},
-			format: "float32",
-			value:  float32(0.1111111111111),
-		},
-		{
-			name:   "can read max f32",
-			bytes:  []byte{0xca, 0x7f, 0x7f, 0xff, 0xff},
-			format: "float32",
-			value:  float32(math.MaxFloat32),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "float32?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional negative f32",
-			bytes:  []byte{0xca, 0xbf, 0x0, 0x0, 0x0},
-			format: "float32?",
-			value:  container.Some(float32(-0.5)),
-		},
-		{
-			name:   "can read optional zero f32",
-			bytes:  []byte{0xca, 0x0, 0x0, 0x0, 0x0},
-			format: "float32?",
-			value:  container.Some(float32(0)),
-		},
-		{
-			name:   "can read optional f32",
-			bytes:  []byte{0xca, 0x3d, 0xe3, 0x8e, 0x39},
-			format: "float32?",
-			value:  container.Some(float32(0.1111111111111)),
-		},
-		{
-			name:   "can read optional max f32",
-			bytes:  []byte{0xca, 0x7f, 0x7f, 0xff, 0xff},
-			format: "float32?",
-			value:  container.Some(float32(math.MaxFloat32)),
-		},
-	})
+	runReadCases(t, readF32Cases)
+}
+
+var readF64Cases = []readcase{
+	{
+		name:   "can read negative f64",
+		bytes:  []byte{0xcb, 0xbf, 0xe0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		format: "float64",
+		value:  float64(-0.5),
+	},
+	{
+		name:   "can read zero f64",
+		bytes:  []byte{0xcb, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		format: "float64",
+		value:  float64(0),
+	},
+	{
+		name:   "can read f64",
+		bytes:  []byte{0xcb, 0x3f, 0xbc, 0x71, 0xc7, 0x1c, 0x71, 0xc3, 0xfc},
+		format: "float64",
+		value:  float64(0.1111111111111),
+	},
+	{
+		name:   "can read max f64",
+		bytes:  []byte{0xcb, 0x7f, 0xef, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		format: "float64",
+		value:  float64(math.MaxFloat64),
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "float64?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional negative f64",
+		bytes:  []byte{0xcb, 0xbf, 0xe0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		format: "float64?",
+		value:  container.Some(float64(-0.5)),
+	},
+	{
+		name:   "can read optional zero f64",
+		bytes:  []byte{0xcb, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
+		format: "float64?",
+		value:  container.Some(float64(0)),
+	},
+	{
+		name:   "can read optional f64",
+		bytes:  []byte{0xcb, 0x3f, 0xbc, 0x71, 0xc7, 0x1c, 0x71, 0xc3, 0xfc},
+		format: "float64?",
+		value:  container.Some(float64(0.1111111111111)),
+	},
+	{
+		name:   "can read optional max f64",
+		bytes:  []byte{0xcb, 0x7f, 0xef, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		format: "float64?",
+		value:  container.Some(float64(math.MaxFloat64)),
+	},
 }
 
 func TestReadF64(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read negative f64",
-			bytes:  []byte{0xcb, 0xbf, 0xe0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
-			format: "float64",
-			value:  float64(-0.5),
-		},
-		{
-			name:   "can read zero f64",
-			bytes:  []byte{0xcb, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
-			format: "float64",
-			value:  float64(0),
-		},
-		{
-			name:   "can read f64",
-			bytes:  []byte{0xcb, 0x3f, 0xbc, 0x71, 0xc7, 0x1c, 0x71, 0xc3, 0xfc},
-			format: "float64",
-			value:  float64(0.1111111111111),
-		},
-		{
-			name:   "can read max f64",
-			bytes:  []byte{0xcb, 0x7f, 0xef, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
-			format: "float64",
-			value:  float64(math.MaxFloat64),
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "float64?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional negative f64",
-			bytes:  []byte{0xcb, 0xbf, 0xe0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
-			format: "float64?",
-			value:  container.Some(float64(-0.5)),
-		},
-		{
-			name:   "can read optional zero f64",
-			bytes:  []byte{0xcb, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0},
-			format: "float64?",
-			value:  container.Some(float64(0)),
-		},
-		{
-			name:   "can read optional f64",
-			bytes:  []byte{0xcb, 0x3f, 0xbc, 0x71, 0xc7, 0x1c, 0x71, 0xc3, 0xfc},
-			format: "float64?",
-			value:  container.Some(float64(0.1111111111111)),
-		},
-		{
-			name:   "can read optional max f64",
-			bytes:  []byte{0xcb, 0x7f, 0xef, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
-			format: "float64?",
-			value:  container.Some(float64(math.MaxFloat64)),
-		},
-	})
+	runReadCases(t, readF64Cases)
+}
+
+var readBytesCases = []readcase{
+	{
+		name:   "can read nil",
+		bytes:  []byte{0xc0},
+		format: "bytes",
+		value:  []byte{},
+	},
+	{
+		name:   "can read bytes",
+		bytes:  []byte{0xc4, 0x1, 0x1},
+		format: "bytes",
+		value:  []byte{1},
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "bytes?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional bytes",
+		bytes:  []byte{0xc4, 0x1, 0x1},
+		format: "bytes?",
+		value:  container.Some([]byte{1}),
+	},
 }
 
 func TestReadBytes(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can read nil",
-			bytes:  []byte{0xc0},
-			format: "bytes",
-			value:  []byte{},
-		},
-		{
-			name:   "can read bytes",
-			bytes:  []byte{0xc4, 0x1, 0x1},
-			format: "bytes",
-			value:  []byte{1},
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "bytes?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional bytes",
-			bytes:  []byte{0xc4, 0x1, 0x1},
-			format: "bytes?",
-			value:  container.Some([]byte{1}),
-		},
-	})
+	runReadCases(t, readBytesCases)
+}
+
+var readStringCases = []readcase{
+	{
+		name:   "can empty string",
+		bytes:  []byte{0xa0},
+		format: "string",
+		value:  "",
+	},
+	{
+		name:   "can read string",
+		bytes:  []byte{0xab, 0x73, 0x6f, 0x6d, 0x65, 0x20, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67},
+		format: "string",
+		value:  "some string",
+	},
+	{
+		name:   "can optional nil",
+		bytes:  []byte{192},
+		format: "string?",
+		value:  container.None(),
+	},
+	{
+		name:   "can read optional empty string",
+		bytes:  []byte{0xa0},
+		format: "string?",
+		value:  container.Some(""),
+	},
+	{
+		name:   "can read optional string",
+		bytes:  []byte{0xab, 0x73, 0x6f, 0x6d, 0x65, 0x20, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67},
+		format: "string?",
+		value:  container.Some("some string"),
+	},
 }
 
 func TestReadString(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "can empty string",
-			bytes:  []byte{0xa0},
-			format: "string",
-			value:  "",
-		},
-		{
-			name:   "can read string",
-			bytes:  []byte{0xab, 0x73, 0x6f, 0x6d, 0x65, 0x20, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67},
-			format: "string",
-			value:  "some string",
-		},
-		{
-			name:   "can optional nil",
-			bytes:  []byte{192},
-			format: "string?",
-			value:  container.None(),
-		},
-		{
-			name:   "can read optional empty string",
-			bytes:  []byte{0xa0},
-			format: "string?",
-			value:  container.Some(""),
-		},
-		{
-			name:   "can read optional string",
-			bytes:  []byte{0xab, 0x73, 0x6f, 0x6d, 0x65, 0x20, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67},
-			format: "string?",
-			value:  container.Some("some string"),
-		},
-	})
+	runReadCases(t, readStringCases)
+}
+
+var readArrayCases = []readcase{
+	{
+		name:   "nil",
+		bytes:  []byte{192},
+		format: "array",
+		value:  []interface{}{},
+		fn1:    nil,
+	},
+	{
+		name:   "[]int8",
+		bytes:  []byte{146, 208, 128, 127},
+		format: "array",
+		value:  []interface{}{int8(math.MinInt8), int8(math.MaxInt8)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI8()
+		},
+	},
+	{
+		name:   "[]int16",
+		bytes:  []byte{146, 209, 128, 0, 209, 127, 255},
+		format: "array",
+		value:  []interface{}{int16(math.MinInt16), int16(math.MaxInt16)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI16()
+		},
+	},
+	{
+		name:   "[]int32",
+		bytes:  []byte{146, 210, 128, 0, 0, 0, 210, 127, 255, 255, 255},
+		format: "array",
+		value:  []interface{}{int32(math.MinInt32), int32(math.MaxInt32)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI32()
+		},
+	},
+	{
+		name:   "[]int64",
+		bytes:  []byte{146, 211, 128, 0, 0, 0, 0, 0, 0, 0, 211, 127, 255, 255, 255, 255, 255, 255, 255},
+		format: "array",
+		value:  []interface{}{int64(math.MinInt64), int64(math.MaxInt64)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI64()
+		},
+	},
+	{
+		name:   "[]uint8",
+		bytes:  []byte{146, 0, 204, 255},
+		format: "array",
+		value:  []interface{}{uint8(0), uint8(math.MaxUint8)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU8()
+		},
+	},
+	{
+		name:   "[]uint16",
+		bytes:  []byte{146, 0, 205, 255, 255},
+		format: "array",
+		value:  []interface{}{uint16(0), uint16(math.MaxUint16)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU16()
+		},
+	},
+	{
+		name:   "[]uint32",
+		bytes:  []byte{146, 0, 206, 255, 255, 255, 255},
+		format: "array",
+		value:  []interface{}{uint32(0), uint32(math.MaxUint32)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU32()
+		},
+	},
+	{
+		name:   "[]uint64",
+		bytes:  []byte{146, 0, 207, 255, 255, 255, 255, 255, 255, 255, 255},
+		format: "array",
+		value:  []interface{}{uint64(0), uint64(math.MaxUint64)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU64()
+		},
+	},
+	{
+		name:   "[]float32",
+		bytes:  []byte{146, 202, 63, 26, 203, 4, 202, 63, 112, 197, 52},
+		format: "array",
+		value:  []interface{}{float32(0.6046603), float32(0.9405091)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadF32()
+		},
+	},
+	{
+		name:   "[]float64",
+		bytes:  []byte{146, 203, 63, 229, 68, 19, 113, 217, 165, 93, 203, 63, 220, 3, 130, 93, 189, 166, 190},
+		format: "array",
+		value:  []interface{}{float64(0.6645600532184904), float64(0.4377141871869802)},
+		fn1: func(reader Read) interface{} {
+			return reader.ReadF64()
+		},
+	},
+	{
+		name:   "optional nil",
+		bytes:  []byte{192},
+		format: "array?",
+		value:  container.None(),
+	},
+	{
+		name:   "optional []int8",
+		bytes:  []byte{146, 208, 128, 127},
+		format: "array?",
+		value:  container.Some([]interface{}{int8(math.MinInt8), int8(math.MaxInt8)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI8()
+		},
+	},
+	{
+		name:   "optional []int16",
+		bytes:  []byte{146, 209, 128, 0, 209, 127, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{int16(math.MinInt16), int16(math.MaxInt16)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI16()
+		},
+	},
+	{
+		name:   "optional []int32",
+		bytes:  []byte{146, 210, 128, 0, 0, 0, 210, 127, 255, 255, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{int32(math.MinInt32), int32(math.MaxInt32)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI32()
+		},
+	},
+	{
+		name:   "optional []int64",
+		bytes:  []byte{146, 211, 128, 0, 0, 0, 0, 0, 0, 0, 211, 127, 255, 255, 255, 255, 255, 255, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{int64(math.MinInt64), int64(math.MaxInt64)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadI64()
+		},
+	},
+	{
+		name:   "optional []uint8",
+		bytes:  []byte{146, 0, 204, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{uint8(0), uint8(math.MaxUint8)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU8()
+		},
+	},
+	{
+		name:   "optional []uint16",
+		bytes:  []byte{146, 0, 205, 255, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{uint16(0), uint16(math.MaxUint16)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU16()
+		},
+	},
+	{
+		name:   "optional []uint32",
+		bytes:  []byte{146, 0, 206, 255, 255, 255, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{uint32(0), uint32(math.MaxUint32)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU32()
+		},
+	},
+	{
+		name:   "optional []uint64",
+		bytes:  []byte{146, 0, 207, 255, 255, 255, 255, 255, 255, 255, 255},
+		format: "array?",
+		value:  container.Some([]interface{}{uint64(0), uint64(math.MaxUint64)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadU64()
+		},
+	},
+	{
+		name:   "optional []float32",
+		bytes:  []byte{146, 202, 63, 26, 203, 4, 202, 63, 112, 197, 52},
+		format: "array?",
+		value:  container.Some([]interface{}{float32(0.6046603), float32(0.9405091)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadF32()
+		},
+	},
+	{
+		name:   "optional []float64",
+		bytes:  []byte{146, 203, 63, 229, 68, 19, 113, 217, 165, 93, 203, 63, 220, 3, 130, 93, 189, 166, 190},
+		format: "array?",
+		value:  container.Some([]interface{}{float64(0.6645600532184904), float64(0.4377141871869802)}),
+		fn1: func(reader Read) interface{} {
+			return reader.ReadF64()
+		},
+	},
 }
 
 func TestReadArray(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "nil",
-			bytes:  []byte{192},
-			format: "array",
-			value:  []interface{}{},
-			fn1:    nil,
-		},
-		{
-			name:   "[]int8",
-			bytes:  []byte{146, 208, 128, 127},
-			format: "array",
-			value:  []interface{}{int8(math.MinInt8), int8(math.MaxInt8)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI8()
-			},
-		},
-		{
-			name:   "[]int16",
-			bytes:  []byte{146, 209, 128, 0, 209, 127, 255},
-			format: "array",
-			value:  []interface{}{int16(math.MinInt16), int16(math.MaxInt16)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI16()
-			},
-		},
-		{
-			name:   "[]int32",
-			bytes:  []byte{146, 210, 128, 0, 0, 0, 210, 127, 255, 255, 255},
-			format: "array",
-			value:  []interface{}{int32(math.MinInt32), int32(math.MaxInt32)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI32()
-			},
-		},
-		{
-			name:   "[]int64",
-			bytes:  []byte{146, 211, 128, 0, 0, 0, 0, 0, 0, 0, 211, 127, 255, 255, 255, 255, 255, 255, 255},
-			format: "array",
-			value:  []interface{}{int64(math.MinInt64), int64(math.MaxInt64)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI64()
-			},
-		},
-		{
-			name:   "[]uint8",
-			bytes:  []byte{146, 0, 204, 255},
-			format: "array",
-			value:  []interface{}{uint8(0), uint8(math.MaxUint8)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU8()
-			},
-		},
-		{
-			name:   "[]uint16",
-			bytes:  []byte{146, 0, 205, 255, 255},
-			format: "array",
-			value:  []interface{}{uint16(0), uint16(math.MaxUint16)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU16()
-			},
-		},
-		{
-			name:   "[]uint32",
-			bytes:  []byte{146, 0, 206, 255, 255, 255, 255},
-			format: "array",
-			value:  []interface{}{uint32(0), uint32(math.MaxUint32)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU32()
-			},
-		},
-		{
-			name:   "[]uint64",
-			bytes:  []byte{146, 0, 207, 255, 255, 255, 255, 255, 255, 255, 255},
-			format: "array",
-			value:  []interface{}{uint64(0), uint64(math.MaxUint64)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU64()
-			},
-		},
-		{
-			name:   "[]float32",
-			bytes:  []byte{146, 202, 63, 26, 203, 4, 202, 63, 112, 197, 52},
-			format: "array",
-			value:  []interface{}{float32(0.6046603), float32(0.9405091)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadF32()
-			},
-		},
-		{
-			name:   "[]float64",
-			bytes:  []byte{146, 203, 63, 229, 68, 19, 113, 217, 165, 93, 203, 63, 220, 3, 130, 93, 189, 166, 190},
-			format: "array",
-			value:  []interface{}{float64(0.6645600532184904), float64(0.4377141871869802)},
-			fn1: func(reader Read) interface{} {
-				return reader.ReadF64()
-			},
-		},
-		{
-			name:   "optional nil",
-			bytes:  []byte{192},
-			format: "array?",
-			value:  container.None(),
-		},
-		{
-			name:   "optional []int8",
-			bytes:  []byte{146, 208, 128, 127},
-			format: "array?",
-			value:  container.Some([]interface{}{int8(math.MinInt8), int8(math.MaxInt8)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI8()
-			},
-		},
-		{
-			name:   "optional []int16",
-			bytes:  []byte{146, 209, 128, 0, 209, 127, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{int16(math.MinInt16), int16(math.MaxInt16)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI16()
-			},
-		},
-		{
-			name:   "optional []int32",
-			bytes:  []byte{146, 210, 128, 0, 0, 0, 210, 127, 255, 255, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{int32(math.MinInt32), int32(math.MaxInt32)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI32()
-			},
-		},
-		{
-			name:   "optional []int64",
-			bytes:  []byte{146, 211, 128, 0, 0, 0, 0, 0, 0, 0, 211, 127, 255, 255, 255, 255, 255, 255, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{int64(math.MinInt64), int64(math.MaxInt64)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadI64()
-			},
-		},
-		{
-			name:   "optional []uint8",
-			bytes:  []byte{146, 0, 204, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{uint8(0), uint8(math.MaxUint8)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU8()
-			},
-		},
-		{
-			name:   "optional []uint16",
-			bytes:  []byte{146, 0, 205, 255, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{uint16(0), uint16(math.MaxUint16)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU16()
-			},
-		},
-		{
-			name:   "optional []uint32",
-			bytes:  []byte{146, 0, 206, 255, 255, 255, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{uint32(0), uint32(math.MaxUint32)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU32()
-			},
-		},
-		{
-			name:   "optional []uint64",
-			bytes:  []byte{146, 0, 207, 255, 255, 255, 255, 255, 255, 255, 255},
-			format: "array?",
-			value:  container.Some([]interface{}{uint64(0), uint64(math.MaxUint64)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadU64()
-			},
-		},
-		{
-			name:   "optional []float32",
-			bytes:  []byte{146, 202, 63, 26, 203, 4, 202, 63, 112, 197, 52},
-			format: "array?",
-			value:  container.Some([]interface{}{float32(0.6046603), float32(0.9405091)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadF32()
-			},
-		},
-		{
-			name:   "optional []float64",
-			bytes:  []byte{146, 203, 63, 229, 68, 19, 113, 217, 165, 93, 203, 63, 220, 3, 130, 93, 189, 166, 190},
-			format: "array?",
-			value:  container.Some([]interface{}{float64(0.6645600532184904), float64(0.4377141871869802)}),
-			fn1: func(reader Read) interface{} {
-				return reader.ReadF64()
-			},
-		},
-	})
+	runReadCases(t, readArrayCases)
+}
+
+var readMapCases = []readcase{
+	{
+		name:   "nil",
+		bytes:  []byte{192},
+		format: "map",
+		value:  map[interface{}]interface{}{},
+		fn2:    nil,
+	},
+	{
+		name:   "map[string]int64",
+		bytes:  []byte{131, 164, 107, 101, 121, 51, 3, 164, 107, 101, 121, 49, 1, 164, 107, 101, 121, 50, 2},
+		format: "map",
+		value: map[interface{}]interface{}{
+			"key1": int64(1),
+			"key2": int64(2),
+			"key3": int64(3),
+		},
+		fn2: func(reader Read) (interface{}, interface{}) {
+			key := reader.ReadString()
+			val := reader.ReadI64()
+			return key, val
+		},
+	},
+	{
+		name:   "map[string]string",
+		bytes:  []byte{131, 164, 107, 101, 121, 49, 164, 118, 97, 108, 49, 164, 107, 101, 121, 50, 164, 118, 97, 108, 50, 164, 107, 101, 121, 51, 164, 118, 97, 108, 51},
+		format: "map",
+		value: map[interface{}]interface{}{
+			"key1": "val1",
+			"key2": "val2",
+			"key3": "val3",
+		},
+		fn2: func(reader Read) (interface{}, interface{}) {
+			key := reader.ReadString()
+			val := reader.ReadString()
+			return key, val
+		},
+	},
+	{
+		name:   "optional nil",
+		bytes:  []byte{192},
+		format: "map?",
+		value:  container.Some(map[interface{}]interface{}{}),
+		fn2:    nil,
+	},
+	{
+		name:   "optional map[string]int64",
+		bytes:  []byte{131, 164, 107, 101, 121, 51, 3, 164, 107, 101, 121, 49, 1, 164, 107, 101, 121, 50, 2},
+		format: "map?",
+		value: container.Some(map[interface{}]interface{}{
+			"key1": int64(1),
+			"key2": int64(2),
+			"key3": int64(3),
+		}),
+		fn2: func(reader Read) (interface{}, interface{}) {
+			key := reader.ReadString()
+			val := reader.ReadI64()
+			return key, val
+		},
+	},
+	{
+		name:   "optional map[string]string",
+		bytes:  []byte{131, 164, 107, 101, 121, 49, 164, 118, 97, 108, 49, 164, 107, 101, 121, 50, 164, 118, 97, 108, 50, 164, 107, 101, 121, 51, 164, 118, 97, 108, 51},
+		format: "map?",
+		value: container.Some(map[interface{}]interface{}{
+			"key1": "val1",
+			"key2": "val2",
+			"key3": "val3",
+		}),
+		fn2: func(reader Read) (interface{}, interface{}) {
+			key := reader.ReadString()
+			val := reader.ReadString()
+			return key, val
+		},
+	},
 }
 
 func TestReadMap(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "nil",
-			bytes:  []byte{192},
-			format: "map",
-			value:  map[interface{}]interface{}{},
-			fn2:    nil,
-		},
-		{
-			name:   "map[string]int64",
-			bytes:  []byte{131, 164, 107, 101, 121, 51, 3, 164, 107, 101, 121, 49, 1, 164, 107, 101, 121, 50, 2},
-			format: "map",
-			value: map[interface{}]interface{}{
-				"key1": int64(1),
-				"key2": int64(2),
-				"key3": int64(3),
-			},
-			fn2: func(reader Read) (interface{}, interface{}) {
-				key := reader.ReadString()
-				val := reader.ReadI64()
-				return key, val
-			},
-		},
-		{
-			name:   "map[string]string",
-			bytes:  []byte{131, 164, 107, 101, 121, 49, 164, 118, 97, 108, 49, 164, 107, 101, 121, 50, 164, 118, 97, 108, 50, 164, 107, 101, 121, 51, 164, 118, 97, 108, 51},
-			format: "map",
-			value: map[interface{}]interface{}{
-				"key1": "val1",
-				"key2": "val2",
-				"key3": "val3",
-			},
-			fn2: func(reader Read) (interface{}, interface{}) {
-				key := reader.ReadString()
-				val := reader.ReadString()
-				return key, val
-			},
-		},
-		{
-			name:   "optional nil",
-			bytes:  []byte{192},
-			format: "map?",
-			value:  container.Some(map[interface{}]interface{}{}),
-			fn2:    nil,
-		},
-		{
-			name:   "optional map[string]int64",
-			bytes:  []byte{131, 164, 107, 101, 121, 51, 3, 164, 107, 101, 121, 49, 1, 164, 107, 101, 121, 50, 2},
-			format: "map?",
-			value: container.Some(map[interface{}]interface{}{
-				"key1": int64(1),
-				"key2": int64(2),
-				"key3": int64(3),
-			}),
-			fn2: func(reader Read) (interface{}, interface{}) {
-				key := reader.ReadString()
-				val := reader.ReadI64()
-				return key, val
-			},
-		},
-		{
-			name:   "optional map[string]string",
-			bytes:  []byte{131, 164, 107, 101, 121, 49, 164, 118, 97, 108, 49, 164, 107, 101, 121, 50, 164, 118, 97, 108, 50, 164, 107, 101, 121, 51, 164, 118, 97, 108, 51},
-			format: "map?",
-			value: container.Some(map[interface{}]interface{}{
-				"key1": "val1",
-				"key2": "val2",
-				"key3": "val3",
-			}),
-			fn2: func(reader Read) (interface{}, interface{}) {
-				key := reader.ReadString()
-				val := reader.ReadString()
-				return key, val
-			},
-		},
-	})
+	runReadCases(t, readMapCases)
+}
+
+var readBigIntCases = []readcase{
+	{
+		name:   "nil",
+		bytes:  []byte{192},
+		format: "bigint",
+		value:  nil,
+	},
+	{
+		name:   "zero",
+		bytes:  []byte{161, 48},
+		format: "bigint",
+		value:  big.NewInt(0),
+	},
+	{
+		name:   "maxInt64",
+		bytes:  []byte{179, 57, 50, 50, 51, 51, 55, 50, 48, 51, 54, 56, 53, 52, 55, 55, 53, 56, 48, 55},
+		format: "bigint",
+		value:  big.NewInt(math.MaxInt64),
+	},
+	{
+		name:   "optional nil",
+		bytes:  []byte{192},
+		format: "bigint",
+		value:  container.None(),
+	},
+	{
+		name:   "optional zero",
+		bytes:  []byte{161, 48},
+		format: "bigint",
+		value:  container.Some(big.NewInt(0)),
+	},
+	{
+		name:   "optional maxInt64",
+		bytes:  []byte{179, 57, 50, 50, 51, 51, 55, 50, 48, 51, 54, 56, 53, 52, 55, 55, 53, 56, 48, 55},
+		format: "bigint",
+		value:  container.Some(big.NewInt(math.MaxInt64)),
+	},
 }
 
 func TestReadBigInt(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "nil",
-			bytes:  []byte{192},
-			format: "bigint",
-			value:  nil,
-		},
-		{
-			name:   "zero",
-			bytes:  []byte{161, 48},
-			format: "bigint",
-			value:  big.NewInt(0),
-		},
-		{
-			name:   "maxInt64",
-			bytes:  []byte{179, 57, 50, 50, 51, 51, 55, 50, 48, 51, 54, 56, 53, 52, 55, 55, 53, 56, 48, 55},
-			format: "bigint",
-			value:  big.NewInt(math.MaxInt64),
-		},
-		{
-			name:   "optional nil",
-			bytes:  []byte{192},
-			format: "bigint",
-			value:  container.None(),
-		},
-		{
-			name:   "optional zero",
-			bytes:  []byte{161, 48},
-			format: "bigint",
-			value:  container.Some(big.NewInt(0)),
-		},
-		{
-			name:   "optional maxInt64",
-			bytes:  []byte{179, 57, 50, 50, 51, 51, 55, 50, 48, 51, 54, 56, 53, 52, 55, 55, 53, 56, 48, 55},
-			format: "bigint",
-			value:  container.Some(big.NewInt(math.MaxInt64)),
-		},
-	})
+	runReadCases(t, readBigIntCases)
+}
+
+var readBigNumberCases = []readcase{
+	{
+		name:   "nil",
+		bytes:  []byte{192},
+		format: "bignumber",
+		value:  nil,
+	},
+	{
+		name:   "fraction",
+		bytes:  []byte{166, 45, 49, 50, 46, 53, 54},
+		format: "bignumber",
+		value:  big.NewNumber(-1256, -2),
+	},
+	{
+		name:   "exponential",
+		bytes:  []byte{165, 49, 101, 43, 50, 49},
+		format: "bignumber",
+		value:  big.NewNumber(1, 21),
+	},
+	{
+		name:   "optional nil",
+		bytes:  []byte{192},
+		format: "bignumber?",
+		value:  container.None(),
+	},
+	{
+		name:   "optional fraction",
+		bytes:  []byte{166, 45, 49, 50, 46, 53, 54},
+		format: "bignumber?",
+		value:  container.Some(big.NewNumber(-1256, -2)),
+	},
+}
+
+func TestReadBigNumber(t *testing.T) {
+	runReadCases(t, readBigNumberCases)
+}
+
+func TestReadBigNumberOutOfRange(t *testing.T) {
+	reader := NewReadDecoderWithOptions(NewContext(""), []byte{171, '1', 'e', '1', '0', '0', '0', '0', '0', '0', '0', '0'}, ReadOptions{})
+	if value := reader.ReadBigNumber(); value != nil || reader.Err() == nil {
+		t.Errorf("Bad value, got: %v %v, want: <nil> and an error", value, reader.Err())
+	}
+}
+
+var readJsonCases = []readcase{
+	{
+		name:   "nil",
+		bytes:  []byte{192},
+		format: "json",
+		value:  nil,
+	},
+	{
+		name:   "obj",
+		bytes:  []byte{217, 38, 123, 34, 107, 101, 121, 49, 34, 58, 49, 44, 34, 107, 101, 121, 50, 34, 58, 34, 115, 116, 114, 105, 110, 103, 34, 44, 34, 107, 101, 121, 51, 34, 58, 116, 114, 117, 101, 125},
+		format: "json",
+		value:  fastjson.MustParse(`{"key1":1,"key2":"string","key3":true}`),
+	},
+	{
+		name:   "optional nil",
+		bytes:  []byte{192},
+		format: "json",
+		value:  container.None(),
+	},
+	{
+		name:   "optional obj",
+		bytes:  []byte{217, 38, 123, 34, 107, 101, 121, 49, 34, 58, 49, 44, 34, 107, 101, 121, 50, 34, 58, 34, 115, 116, 114, 105, 110, 103, 34, 44, 34, 107, 101, 121, 51, 34, 58, 116, 114, 117, 101, 125},
+		format: "json",
+		value:  container.Some(fastjson.MustParse(`{"key1":1,"key2":"string","key3":true}`)),
+	},
 }
 
 func TestReadJson(t *testing.T) {
-	runReadCases(t, []readcase{
-		{
-			name:   "nil",
-			bytes:  []byte{192},
-			format: "json",
-			value:  nil,
-		},
-		{
-			name:   "obj",
-			bytes:  []byte{217, 38, 123, 34, 107, 101, 121, 49, 34, 58, 49, 44, 34, 107, 101, 121, 50, 34, 58, 34, 115, 116, 114, 105, 110, 103, 34, 44, 34, 107, 101, 121, 51, 34, 58, 116, 114, 117, 101, 125},
-			format: "json",
-			value:  fastjson.MustParse(`{"key1":1,"key2":"string","key3":true}`),
-		},
-		{
-			name:   "optional nil",
-			bytes:  []byte{192},
-			format: "json",
-			value:  container.None(),
-		},
-		{
-			name:   "optional obj",
-			bytes:  []byte{217, 38, 123, 34, 107, 101, 121, 49, 34, 58, 49, 44, 34, 107, 101, 121, 50, 34, 58, 34, 115, 116, 114, 105, 110, 103, 34, 44, 34, 107, 101, 121, 51, 34, 58, 116, 114, 117, 101, 125},
-			format: "json",
-			value:  container.Some(fastjson.MustParse(`{"key1":1,"key2":"string","key3":true}`)),
-		},
-	})
+	runReadCases(t, readJsonCases)
+}
+
+func TestReadDecodeError(t *testing.T) {
+	context := NewContext("Deserializing MyObject")
+	reader := NewReadDecoderWithOptions(context, []byte{0x01, 0xa1, 0x61, 0xd2, 0x01}, ReadOptions{})
+
+	if v := reader.ReadI32(); v != 1 || reader.Err() != nil {
+		t.Fatalf("Unexpected result, got: %v, %v", v, reader.Err())
+	}
+
+	context.Push("property", "bool", "type found, reading property")
+	if v := reader.ReadBool(); v || reader.Err() == nil {
+		t.Fatalf("Expected error, got: %v", v)
+	}
+	context.Pop()
+
+	err, ok := reader.Err().(*DecodeError)
+	if !ok {
+		t.Fatalf("Bad error type: %T", reader.Err())
+	}
+	if err.Expected != format.TRUE || err.Found != format.Format(0xa1) || err.Offset != 1 {
+		t.Errorf("Bad error, got: %+v", err)
+	}
+	expected := "Property must be of type 'bool'. Found string\n  Context: Deserializing MyObject\n    at property: bool >> type found, reading property"
+	if err.Error() != expected {
+		t.Errorf("Bad message, got: %q, want: %q", err.Error(), expected)
+	}
+
+	// errors are sticky
+	if v := reader.ReadString(); v != "" || reader.Err() != err {
+		t.Errorf("Expected sticky error, got: %v, %v", v, reader.Err())
+	}
+}
+
+func TestReadDecodeErrorTruncated(t *testing.T) {
+	reader := NewReadDecoderWithOptions(NewContext(""), []byte{0xd2, 0x01}, ReadOptions{})
+	if v := reader.ReadI32(); v != 0 {
+		t.Errorf("Bad value, got: %v", v)
+	}
+	err, ok := reader.Err().(*DecodeError)
+	if !ok {
+		t.Fatalf("Bad error type: %T", reader.Err())
+	}
+	if err.Expected != format.ERROR || err.Found != format.ERROR || err.Offset != 1 {
+		t.Errorf("Bad error, got: %+v", err)
+	}
+
+	reader = NewReadDecoderWithOptions(NewContext(""), []byte{0xdb, 0xff, 0xff, 0xff, 0xff, 0x61}, ReadOptions{})
+	if v := reader.ReadString(); v != "" || reader.Err() == nil {
+		t.Errorf("Expected error, got: %v", v)
+	}
+}
+
+func TestReadDecoderPanics(t *testing.T) {
+	if runtime.Compiler == "tinygo" {
+		t.Log("Skipping due tinygo limitations")
+		return
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("The code did not panic")
+		}
+	}()
+
+	NewReadDecoder(NewContext(""), []byte{0xc0}).ReadBool()
+}
+
+func TestReadStrict(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		read func(reader *ReadDecoder)
+	}{
+		{"nil string", []byte{0xc0}, func(r *ReadDecoder) { r.ReadString() }},
+		{"fixarray string", []byte{0x91, 0x61}, func(r *ReadDecoder) { r.ReadString() }},
+		{"invalid utf-8", []byte{0xa2, 0xc3, 0x28}, func(r *ReadDecoder) { r.ReadString() }},
+		{"nil map", []byte{0xc0}, func(r *ReadDecoder) { r.ReadMapLength() }},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			lenient := NewReadDecoderWithOptions(NewContext(""), tcase.data, ReadOptions{})
+			tcase.read(lenient)
+			if err := lenient.Err(); err != nil {
+				t.Errorf("Unexpected lenient error: %v", err)
+			}
+
+			strict := NewReadDecoderWithOptions(NewContext(""), tcase.data, ReadOptions{Strict: true})
+			tcase.read(strict)
+			if strict.Err() == nil {
+				t.Errorf("Expected strict error")
+			}
+		})
+	}
+}
+
+func TestReadStrictEmpty(t *testing.T) {
+	// empty bytes and arrays are written as nil
+	cases := []struct {
+		name string
+		read func(reader *ReadDecoder) int
+	}{
+		{"bytes", func(r *ReadDecoder) int { return len(r.ReadBytes()) }},
+		{"bytes view", func(r *ReadDecoder) int { return len(r.ReadBytesView()) }},
+		{"bytes length", func(r *ReadDecoder) int { return int(r.ReadBytesLength()) }},
+		{"array", func(r *ReadDecoder) int {
+			return len(r.ReadArray(func(reader Read) interface{} { return reader.ReadI8() }))
+		}},
+		{"array length", func(r *ReadDecoder) int { return int(r.ReadArrayLength()) }},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			strict := NewReadDecoderWithOptions(NewContext(""), []byte{0xc0}, ReadOptions{Strict: true})
+			if n := tcase.read(strict); n != 0 || strict.Finish() != nil {
+				t.Errorf("Bad strict read, got: %d %v, want: 0 <nil>", n, strict.Err())
+			}
+		})
+	}
+}
+
+func TestReadStrictFinish(t *testing.T) {
+	data := []byte{0x01, 0x02}
+
+	lenient := NewReadDecoderWithOptions(NewContext(""), data, ReadOptions{})
+	lenient.ReadU8()
+	if err := lenient.Finish(); err != nil {
+		t.Errorf("Unexpected lenient error: %v", err)
+	}
+
+	strict := NewReadDecoderWithOptions(NewContext(""), data, ReadOptions{Strict: true})
+	strict.ReadU8()
+	err, ok := strict.Finish().(*DecodeError)
+	if !ok {
+		t.Fatalf("Expected *DecodeError, got: %v", strict.Err())
+	}
+	if err.Offset != 1 {
+		t.Errorf("Bad offset, got: %v, want: 1", err.Offset)
+	}
+
+	var v uint8
+	if err := UnmarshalWithOptions(data, &v, ReadOptions{Strict: true}); err == nil {
+		t.Errorf("Expected trailing bytes error")
+	}
+	if err := Unmarshal(data, &v); err != nil || v != 1 {
+		t.Errorf("Bad lenient unmarshal, got: %v, %v", v, err)
+	}
 }
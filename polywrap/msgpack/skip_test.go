@@ -0,0 +1,133 @@
+package msgpack
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+)
+
+func TestSkip(t *testing.T) {
+	writeStrings := func(encoder Write, key interface{}, value interface{}) {
+		encoder.WriteString(key.(string))
+		encoder.WriteString(value.(string))
+	}
+
+	cases := []struct {
+		name string
+		fn   func(writer Write)
+	}{
+		{"nil", func(w Write) { w.WriteNil() }},
+		{"bool", func(w Write) { w.WriteBool(true) }},
+		{"fixed int", func(w Write) { w.WriteI8(-1) }},
+		{"int8", func(w Write) { w.WriteI8(-100) }},
+		{"int16", func(w Write) { w.WriteI16(-1000) }},
+		{"int32", func(w Write) { w.WriteI32(math.MinInt32) }},
+		{"int64", func(w Write) { w.WriteI64(math.MinInt64) }},
+		{"uint8", func(w Write) { w.WriteU8(200) }},
+		{"uint16", func(w Write) { w.WriteU16(1000) }},
+		{"uint32", func(w Write) { w.WriteU32(math.MaxUint32) }},
+		{"uint64", func(w Write) { w.WriteU64(math.MaxUint64) }},
+		{"float32", func(w Write) { w.WriteFloat32(0.5) }},
+		{"float64", func(w Write) { w.WriteFloat64(0.5) }},
+		{"fixed string", func(w Write) { w.WriteString("value") }},
+		{"string8", func(w Write) { w.WriteString(strings.Repeat("a", 32)) }},
+		{"string16", func(w Write) { w.WriteString(strings.Repeat("a", 256)) }},
+		{"string32", func(w Write) { w.WriteString(strings.Repeat("a", math.MaxUint16+1)) }},
+		{"bytes8", func(w Write) { w.WriteBytes(make([]byte, 10)) }},
+		{"bytes16", func(w Write) { w.WriteBytes(make([]byte, 300)) }},
+		{"bytes32", func(w Write) { w.WriteBytes(make([]byte, math.MaxUint16)) }},
+		{"nested array", func(w Write) {
+			w.WriteValue([]interface{}{int64(1), []interface{}{"a", map[string]interface{}{"b": nil}}})
+		}},
+		{"array16", func(w Write) { w.WriteValue(make([]interface{}, 20)) }},
+		{"map", func(w Write) {
+			w.WriteMap(map[interface{}]interface{}{"key1": "value1", "key2": "value2"}, writeStrings)
+		}},
+		{"fixext", func(w Write) { w.WriteExt(5, make([]byte, 4)) }},
+		{"ext8", func(w Write) { w.WriteExt(5, make([]byte, 3)) }},
+		{"ext16", func(w Write) { w.WriteExt(5, make([]byte, 256)) }},
+		{"ext32", func(w Write) { w.WriteExt(5, make([]byte, math.MaxUint16+1)) }},
+		{"generic map", func(w Write) {
+			w.WriteExtGenericMap(map[interface{}]interface{}{"key": "value"}, writeStrings)
+		}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			writer := NewWriteEncoder(NewContext(""))
+			tcase.fn(writer)
+			writer.WriteString("next")
+
+			reader := NewReadDecoderWithOptions(NewContext(""), writer.Buffer(), ReadOptions{})
+			reader.Skip()
+			if v := reader.ReadString(); v != "next" || reader.Err() != nil {
+				t.Errorf("Bad value after skip, got: %q, %v", v, reader.Err())
+			}
+		})
+	}
+}
+
+func TestSkipErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		bytes []byte
+	}{
+		{"empty", []byte{}},
+		{"reserved", []byte{0xc1}},
+		{"truncated string", []byte{0xa5, 'a'}},
+		{"truncated array", []byte{0xdd, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{"truncated ext", []byte{0xc9, 0xff, 0xff, 0xff, 0xff, 0x05}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewReadDecoderWithOptions(NewContext(""), tcase.bytes, ReadOptions{})
+			reader.Skip()
+			if reader.Err() == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestReadOptionalConsumesNil(t *testing.T) {
+	reader := NewReadDecoder(NewContext(""), []byte{0xc0, 0xc0, 0xc0, 0x01})
+	if v := reader.ReadOptionalString(); v.IsSome() {
+		t.Errorf("Bad value, got: %v", v)
+	}
+	if v := reader.ReadBytes(); v != nil {
+		t.Errorf("Bad value, got: %v", v)
+	}
+	if v := reader.ReadOptionalI32(); v.IsSome() {
+		t.Errorf("Bad value, got: %v", v)
+	}
+	if v := reader.ReadOptionalI32(); !reflect.DeepEqual(v, container.Some(int32(1))) {
+		t.Errorf("Bad value, got: %v", v)
+	}
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	type newer struct {
+		Label string
+		Extra []map[string]int32
+		Tags  []string
+	}
+	data, err := Marshal(newer{Label: "label", Extra: []map[string]int32{{"a": 1}}, Tags: []string{"b"}})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+
+	var actual marshalNested
+	if err := Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	expected := marshalNested{Label: "label", Tags: []string{"b"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Bad value, got: %v, want: %v", actual, expected)
+	}
+}
@@ -0,0 +1,55 @@
+package msgpack
+
+import (
+	"io"
+)
+
+// StreamDecoder reads msgpack values from an io.Reader without loading the
+// whole input in memory. Like any ReadDecoder it can read a sequence of
+// concatenated top-level values, More reports whether another one follows.
+type StreamDecoder struct {
+	*ReadDecoder
+}
+
+// NewStreamDecoder returns a StreamDecoder that records errors instead of
+// panicking, see ReadDecoder.Err.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return NewStreamDecoderWithOptions(r, ReadOptions{})
+}
+
+func NewStreamDecoderWithOptions(r io.Reader, options ReadOptions) *StreamDecoder {
+	context := NewContext("Decoding msgpack stream")
+	return &StreamDecoder{
+		ReadDecoder: newReadDecoder(context, NewDataViewWithReader(context, r), options),
+	}
+}
+
+// More reports whether the input has another value and no error occurred.
+func (sd *StreamDecoder) More() bool {
+	return sd.err == nil && sd.view.Fill(1) > 0
+}
+
+// StreamEncoder writes msgpack values to an io.Writer. Output is buffered,
+// Flush must be called once the last value is written. Write errors are
+// sticky and returned by Flush.
+type StreamEncoder struct {
+	*WriteEncoder
+}
+
+func NewStreamEncoder(w io.Writer) *StreamEncoder {
+	return NewStreamEncoderWithOptions(w, WriteOptions{})
+}
+
+// NewStreamEncoderWithOptions returns a StreamEncoder with the given options.
+// Size is ignored.
+func NewStreamEncoderWithOptions(w io.Writer, options WriteOptions) *StreamEncoder {
+	context := NewContext("Encoding msgpack stream")
+	return &StreamEncoder{
+		WriteEncoder: newWriteEncoder(context, NewDataViewWithWriter(context, w), options),
+	}
+}
+
+// Flush writes the buffered output and returns the first write error.
+func (se *StreamEncoder) Flush() error {
+	return se.view.Flush()
+}
@@ -0,0 +1,95 @@
+package msgpack
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+var (
+	_ Read  = (*StreamDecoder)(nil)
+	_ Write = (*StreamEncoder)(nil)
+)
+
+func TestStreamRoundTrip(t *testing.T) {
+	large := bytes.Repeat([]byte{0xab}, 3*streamChunkSize+7)
+
+	var out bytes.Buffer
+	encoder := NewStreamEncoder(&out)
+	encoder.WriteString("first")
+	encoder.WriteBytes(large)
+	encoder.WriteArrayLength(2)
+	encoder.WriteI32(-100000)
+	encoder.WriteMap(map[interface{}]interface{}{"key": true}, func(encoder Write, key interface{}, value interface{}) {
+		encoder.WriteString(key.(string))
+		encoder.WriteBool(value.(bool))
+	})
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("Flush error: %v", err)
+	}
+
+	decoder := NewStreamDecoder(iotest.OneByteReader(bytes.NewReader(out.Bytes())))
+	var values []interface{}
+	for decoder.More() {
+		value, err := decoder.ReadValue()
+		if err != nil {
+			t.Fatalf("ReadValue error: %v", err)
+		}
+		values = append(values, value)
+	}
+	if err := decoder.Err(); err != nil {
+		t.Fatalf("Decoder error: %v", err)
+	}
+
+	expected := []interface{}{
+		"first",
+		large,
+		[]interface{}{int64(-100000), map[string]interface{}{"key": true}},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Bad values, got: %v, want: %v", len(values), len(expected))
+	}
+}
+
+func TestStreamDecoderTruncated(t *testing.T) {
+	decoder := NewStreamDecoder(bytes.NewReader([]byte{0x92, 0x01}))
+	decoder.ReadArrayLength()
+	decoder.ReadI8()
+	decoder.ReadI8()
+	if err := decoder.Err(); err == nil {
+		t.Errorf("Expected end of input error")
+	}
+	if decoder.More() {
+		t.Errorf("Expected no more values")
+	}
+}
+
+func TestStreamDecoderReadError(t *testing.T) {
+	readErr := errors.New("connection reset")
+	decoder := NewStreamDecoder(iotest.ErrReader(readErr))
+	decoder.ReadString()
+	err, ok := decoder.Err().(*DecodeError)
+	if !ok {
+		t.Fatalf("Expected *DecodeError, got: %v", decoder.Err())
+	}
+	if err.Message != "Read error: connection reset" {
+		t.Errorf("Bad message, got: %v", err.Message)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestStreamEncoderWriteError(t *testing.T) {
+	encoder := NewStreamEncoder(failingWriter{})
+	encoder.WriteBytes(make([]byte, 2*streamChunkSize))
+	encoder.WriteString("after the error")
+	if err := encoder.Flush(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Bad error, got: %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package msgpack
+
+import "strconv"
+
+// containerReader is implemented by ReadDecoder and StreamDecoder, the typed
+// helpers use it to apply the same limits as ReadArray and ReadMap.
+type containerReader interface {
+	capacity(size uint32) int
+	enter() bool
+	leave()
+}
+
+// sortingWriter is implemented by WriteEncoder and StreamEncoder, the typed
+// helpers use it to write map entries in canonical order.
+type sortingWriter interface {
+	canonical() bool
+	writeSortedEntries(entries [][]byte)
+}
+
+// ReadArrayOf reads an array whose items are read by fn, e.g.
+//
+//	ReadArrayOf(reader, Read.ReadI32)
+//
+// Nil is read as an empty array, unless the reader is strict.
+func ReadArrayOf[T any](reader Read, fn func(reader Read) T) []T {
+	size := reader.ReadArrayLength()
+	limits, ok := reader.(containerReader)
+	if !ok {
+		return readArrayItems(reader, size, nil, fn)
+	}
+	data := make([]T, 0, limits.capacity(size))
+	if !limits.enter() {
+		return data
+	}
+	defer limits.leave()
+	return readArrayItems(reader, size, data, fn)
+}
+
+func readArrayItems[T any](reader Read, size uint32, data []T, fn func(reader Read) T) []T {
+	for i := uint32(0); i < size && reader.Err() == nil; i++ {
+		reader.Context().Push(strconv.FormatUint(uint64(i), 10), "array item", "reading array item")
+		data = append(data, fn(reader))
+		reader.Context().Pop()
+	}
+	return data
+}
+
+// WriteArrayOf writes value as an array whose items are written by fn. Unlike
+// WriteArray, an empty slice is written as an empty array.
+func WriteArrayOf[T any](writer Write, value []T, fn func(writer Write, item T)) {
+	writer.WriteArrayLength(uint32(len(value)))
+	for i := range value {
+		writer.Context().Push(strconv.Itoa(i), "array item", "writing array item")
+		fn(writer, value[i])
+		writer.Context().Pop()
+	}
+}
+
+// ReadMapOf reads a map whose keys and values are read by key and value, e.g.
+//
+//	ReadMapOf(reader, Read.ReadString, Read.ReadU64)
+//
+// Nil is read as an empty map, unless the reader is strict.
+func ReadMapOf[K comparable, V any](reader Read, key func(reader Read) K, value func(reader Read) V) map[K]V {
+	size := reader.ReadMapLength()
+	limits, ok := reader.(containerReader)
+	if !ok {
+		return readMapEntries(reader, size, make(map[K]V), key, value)
+	}
+	data := make(map[K]V, limits.capacity(size))
+	if !limits.enter() {
+		return data
+	}
+	defer limits.leave()
+	return readMapEntries(reader, size, data, key, value)
+}
+
+func readMapEntries[K comparable, V any](reader Read, size uint32, data map[K]V, key func(reader Read) K, value func(reader Read) V) map[K]V {
+	for i := uint32(0); i < size && reader.Err() == nil; i++ {
+		k := key(reader)
+		data[k] = value(reader)
+	}
+	return data
+}
+
+// WriteMapOf writes value as a map whose keys and values are written by key
+// and value. Canonical encoders sort the entries like WriteMap does.
+func WriteMapOf[K comparable, V any](writer Write, value map[K]V, key func(writer Write, key K), item func(writer Write, value V)) {
+	writer.WriteMapLength(uint32(len(value)))
+	sorting, ok := writer.(sortingWriter)
+	if !ok || !sorting.canonical() {
+		for k, v := range value {
+			key(writer, k)
+			item(writer, v)
+		}
+		return
+	}
+
+	entries := make([][]byte, 0, len(value))
+	for k, v := range value {
+		entry := NewWriteEncoderWithOptions(writer.Context(), WriteOptions{Canonical: true})
+		key(entry, k)
+		item(entry, v)
+		entries = append(entries, entry.Buffer())
+	}
+	sorting.writeSortedEntries(entries)
+}
+
+// ReadStringMap reads a map with string keys whose values are read by fn.
+func ReadStringMap[T any](reader Read, fn func(reader Read) T) map[string]T {
+	return ReadMapOf(reader, Read.ReadString, fn)
+}
+
+// WriteStringMap writes value as a map with string keys whose values are
+// written by fn.
+func WriteStringMap[T any](writer Write, value map[string]T, fn func(writer Write, value T)) {
+	WriteMapOf(writer, value, Write.WriteString, fn)
+}
+
+// ReadOptionalOf reads nil as a nil pointer and any other item with fn. It
+// nests with the other helpers, e.g. an array of optional strings is read by
+//
+//	ReadArrayOf(reader, func(reader Read) *string {
+//		return ReadOptionalOf(reader, Read.ReadString)
+//	})
+func ReadOptionalOf[T any](reader Read, fn func(reader Read) T) *T {
+	if reader.IsNil() {
+		reader.Skip()
+		return nil
+	}
+	value := fn(reader)
+	return &value
+}
+
+// WriteOptionalOf writes a nil pointer as nil and any other value with fn.
+func WriteOptionalOf[T any](writer Write, value *T, fn func(writer Write, value T)) {
+	if value == nil {
+		writer.WriteNil()
+		return
+	}
+	fn(writer, *value)
+}
+
+func ReadBoolArray(reader Read) []bool {
+	return ReadArrayOf(reader, Read.ReadBool)
+}
+
+func WriteBoolArray(writer Write, value []bool) {
+	WriteArrayOf(writer, value, Write.WriteBool)
+}
+
+func ReadI32Array(reader Read) []int32 {
+	return ReadArrayOf(reader, Read.ReadI32)
+}
+
+func WriteI32Array(writer Write, value []int32) {
+	WriteArrayOf(writer, value, Write.WriteI32)
+}
+
+func ReadI64Array(reader Read) []int64 {
+	return ReadArrayOf(reader, Read.ReadI64)
+}
+
+func WriteI64Array(writer Write, value []int64) {
+	WriteArrayOf(writer, value, Write.WriteI64)
+}
+
+func ReadU32Array(reader Read) []uint32 {
+	return ReadArrayOf(reader, Read.ReadU32)
+}
+
+func WriteU32Array(writer Write, value []uint32) {
+	WriteArrayOf(writer, value, Write.WriteU32)
+}
+
+func ReadU64Array(reader Read) []uint64 {
+	return ReadArrayOf(reader, Read.ReadU64)
+}
+
+func WriteU64Array(writer Write, value []uint64) {
+	WriteArrayOf(writer, value, Write.WriteU64)
+}
+
+func ReadF64Array(reader Read) []float64 {
+	return ReadArrayOf(reader, Read.ReadF64)
+}
+
+func WriteF64Array(writer Write, value []float64) {
+	WriteArrayOf(writer, value, Write.WriteFloat64)
+}
+
+func ReadStringArray(reader Read) []string {
+	return ReadArrayOf(reader, Read.ReadString)
+}
+
+func WriteStringArray(writer Write, value []string) {
+	WriteArrayOf(writer, value, Write.WriteString)
+}
+
+// ReadBytesArray reads an array of binaries. Each item is copied, see
+// ReadBytes.
+func ReadBytesArray(reader Read) [][]byte {
+	return ReadArrayOf(reader, Read.ReadBytes)
+}
+
+func WriteBytesArray(writer Write, value [][]byte) {
+	WriteArrayOf(writer, value, Write.WriteBytes)
+}
@@ -0,0 +1,167 @@
+package msgpack
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTypedHelpers(t *testing.T) {
+	one, two := "one", "two"
+
+	cases := []struct {
+		name  string
+		bytes []byte
+		write func(writer Write)
+		read  func(reader Read) interface{}
+		value interface{}
+	}{
+		{
+			name:  "string array",
+			bytes: []byte{0x92, 0xa1, 'a', 0xa1, 'b'},
+			write: func(w Write) { WriteStringArray(w, []string{"a", "b"}) },
+			read:  func(r Read) interface{} { return ReadStringArray(r) },
+			value: []string{"a", "b"},
+		},
+		{
+			name:  "empty i32 array",
+			bytes: []byte{0x90},
+			write: func(w Write) { WriteI32Array(w, []int32{}) },
+			read:  func(r Read) interface{} { return ReadI32Array(r) },
+			value: []int32{},
+		},
+		{
+			name:  "i32 array",
+			bytes: []byte{0x93, 0x01, 0xff, 0xd1, 0x01, 0x00},
+			write: func(w Write) { WriteI32Array(w, []int32{1, -1, 256}) },
+			read:  func(r Read) interface{} { return ReadI32Array(r) },
+			value: []int32{1, -1, 256},
+		},
+		{
+			name:  "bytes array",
+			bytes: []byte{0x92, 0xc4, 0x01, 0x01, 0xc4, 0x02, 0x02, 0x03},
+			write: func(w Write) { WriteBytesArray(w, [][]byte{{1}, {2, 3}}) },
+			read:  func(r Read) interface{} { return ReadBytesArray(r) },
+			value: [][]byte{{1}, {2, 3}},
+		},
+		{
+			name:  "nested array",
+			bytes: []byte{0x92, 0x91, 0x01, 0x90},
+			write: func(w Write) { WriteArrayOf(w, [][]uint64{{1}, {}}, WriteU64Array) },
+			read:  func(r Read) interface{} { return ReadArrayOf(r, ReadU64Array) },
+			value: [][]uint64{{1}, {}},
+		},
+		{
+			name:  "array of optional strings",
+			bytes: []byte{0x93, 0xa3, 'o', 'n', 'e', 0xc0, 0xa3, 't', 'w', 'o'},
+			write: func(w Write) {
+				WriteArrayOf(w, []*string{&one, nil, &two}, func(w Write, item *string) {
+					WriteOptionalOf(w, item, Write.WriteString)
+				})
+			},
+			read: func(r Read) interface{} {
+				return ReadArrayOf(r, func(r Read) *string { return ReadOptionalOf(r, Read.ReadString) })
+			},
+			value: []*string{&one, nil, &two},
+		},
+		{
+			name:  "optional array",
+			bytes: []byte{0xc0},
+			write: func(w Write) { WriteOptionalOf(w, (*[]bool)(nil), WriteBoolArray) },
+			read:  func(r Read) interface{} { return ReadOptionalOf(r, ReadBoolArray) },
+			value: (*[]bool)(nil),
+		},
+		{
+			name:  "string map",
+			bytes: []byte{0x81, 0xa1, 'a', 0x92, 0x01, 0x02},
+			write: func(w Write) { WriteStringMap(w, map[string][]int64{"a": {1, 2}}, WriteI64Array) },
+			read:  func(r Read) interface{} { return ReadStringMap(r, ReadI64Array) },
+			value: map[string][]int64{"a": {1, 2}},
+		},
+		{
+			name:  "map of maps",
+			bytes: []byte{0x81, 0x07, 0x81, 0xa1, 'x', 0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0},
+			write: func(w Write) {
+				WriteMapOf(w, map[uint32]map[string]float64{7: {"x": 0.5}}, Write.WriteU32, func(w Write, value map[string]float64) {
+					WriteStringMap(w, value, Write.WriteFloat64)
+				})
+			},
+			read: func(r Read) interface{} {
+				return ReadMapOf(r, Read.ReadU32, func(r Read) map[string]float64 {
+					return ReadStringMap(r, Read.ReadF64)
+				})
+			},
+			value: map[uint32]map[string]float64{7: {"x": 0.5}},
+		},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			sizer := NewWriteSizer(NewContext(""))
+			tcase.write(sizer)
+			writer := NewWriteEncoder(NewContext(""))
+			tcase.write(writer)
+			if !bytes.Equal(writer.Buffer(), tcase.bytes) {
+				t.Errorf("Bad bytes, got: %x, want: %x", writer.Buffer(), tcase.bytes)
+			}
+			if int(sizer.Length()) != len(tcase.bytes) {
+				t.Errorf("Bad length, got: %d, want: %d", sizer.Length(), len(tcase.bytes))
+			}
+
+			reader := NewReadDecoder(NewContext(""), tcase.bytes)
+			actual := tcase.read(reader)
+			if !reflect.DeepEqual(actual, tcase.value) {
+				t.Errorf("Bad value, got: %#v, want: %#v", actual, tcase.value)
+			}
+		})
+	}
+}
+
+func TestWriteMapOfCanonical(t *testing.T) {
+	value := map[string]uint8{"c": 3, "a": 1, "b": 2}
+	expected := []byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02, 0xa1, 'c', 0x03}
+
+	for i := 0; i < 10; i++ {
+		writer := NewWriteEncoderWithOptions(NewContext(""), WriteOptions{Canonical: true})
+		WriteStringMap(writer, value, Write.WriteU8)
+		if !bytes.Equal(writer.Buffer(), expected) {
+			t.Fatalf("Bad bytes, got: %x, want: %x", writer.Buffer(), expected)
+		}
+	}
+}
+
+func TestTypedHelpersErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    []byte
+		options ReadOptions
+		read    func(reader Read)
+		message string
+	}{
+		{"item type", []byte{0x92, 0x01, 0xa1, 'a'}, ReadOptions{},
+			func(r Read) { ReadI32Array(r) }, "Property must be of type 'int'. Found string"},
+		{"item context", []byte{0x92, 0x01, 0xa1, 'a'}, ReadOptions{},
+			func(r Read) { ReadI32Array(r) }, "1: array item >> reading array item"},
+		{"huge array", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}, ReadOptions{},
+			func(r Read) { ReadStringArray(r) }, "exceeds the remaining input"},
+		{"strict nil", []byte{0xc0}, ReadOptions{Strict: true},
+			func(r Read) { ReadStringMap(r, Read.ReadString) }, "Property must be of type 'map'. Found nil"},
+		{"depth", []byte{0x91, 0x91, 0x91, 0x01}, ReadOptions{MaxDepth: 2},
+			func(r Read) { ReadArrayOf(r, func(r Read) [][]int32 { return ReadArrayOf(r, ReadI32Array) }) }, "maximum nesting depth of 2"},
+	}
+
+	for _, tcase := range cases {
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewReadDecoderWithOptions(NewContext("Deserializing"), tcase.data, tcase.options)
+			tcase.read(reader)
+			err := reader.Err()
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+			if !strings.Contains(err.Error(), tcase.message) {
+				t.Errorf("Bad error, got: %v, want: %v", err, tcase.message)
+			}
+		})
+	}
+}
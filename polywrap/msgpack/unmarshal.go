@@ -0,0 +1,301 @@
+package msgpack
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/format"
+)
+
+// Unmarshal decodes the msgpack-encoded data and stores the result in the
+// value pointed to by v. It is the inverse of Marshal and follows the same
+// field naming rules. Unknown struct fields are skipped. A nil in the input sets pointers, slices and maps to nil
+// and other values to their zero value. Types registered in DefaultExtRegistry
+// are decoded from their extension. Maps are accepted both bare and wrapped in
+// a GenericMap extension. Empty interfaces receive the values ReadValue returns.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v, ReadOptions{})
+}
+
+// UnmarshalWithOptions is Unmarshal with decoder options. PanicOnError is
+// ignored, errors are always returned. With Strict, trailing bytes after the
+// value are an error, and so is nil for values that are not pointers,
+// interfaces or slices.
+func UnmarshalWithOptions(data []byte, v interface{}, options ReadOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("Unmarshal requires a non-nil pointer, got '" + typeName(reflect.TypeOf(v)) + "'")
+	}
+
+	context := NewContext("Unmarshaling (decoding) " + typeName(rv.Type().Elem()))
+	options.PanicOnError = false
+	reader := NewReadDecoderWithOptions(context, data, options)
+
+	if err := unmarshalValue(reader, rv.Elem()); err != nil {
+		return err
+	}
+	return reader.Finish()
+}
+
+func unmarshalError(reader *ReadDecoder, message string) error {
+	reader.fail(message, format.ERROR, reader.format, reader.offset)
+	return reader.Err()
+}
+
+func unmarshalValue(reader *ReadDecoder, v reflect.Value) error {
+	if reader.readNil() {
+		if reader.options.Strict && !nilable(v.Type()) {
+			return unmarshalError(reader, "Found nil for non-optional type '"+typeName(v.Type())+"'")
+		}
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if DefaultExtRegistry.lookupGoType(v.Type()) != nil {
+		value, err := DefaultExtRegistry.Read(reader)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || !rv.Type().AssignableTo(v.Type()) {
+			return unmarshalError(reader, "Extension decoded to '"+typeName(reflect.TypeOf(value))+
+				"', expected '"+typeName(v.Type())+"'")
+		}
+		v.Set(rv)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		switch v.Type().Elem() {
+		case bigIntType:
+			v.Set(reflect.ValueOf(reader.ReadBigInt()))
+			return reader.Err()
+		case bigNumberType:
+			v.Set(reflect.ValueOf(reader.ReadBigNumber()))
+			return reader.Err()
+		case jsonValueType:
+			v.Set(reflect.ValueOf(reader.ReadJson()))
+			return reader.Err()
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(reader, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return unmarshalError(reader, "Unsupported type '"+typeName(v.Type())+"'")
+		}
+		value := reader.readValue()
+		if reader.err != nil {
+			return reader.Err()
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() {
+			return unmarshalError(reader, "Value decoded to nil, expected '"+typeName(v.Type())+"'")
+		}
+		v.Set(rv)
+	case reflect.Bool:
+		v.SetBool(reader.ReadBool())
+	case reflect.Int8:
+		v.SetInt(int64(reader.ReadI8()))
+	case reflect.Int16:
+		v.SetInt(int64(reader.ReadI16()))
+	case reflect.Int32:
+		v.SetInt(int64(reader.ReadI32()))
+	case reflect.Int, reflect.Int64:
+		v.SetInt(reader.ReadI64())
+	case reflect.Uint8:
+		v.SetUint(uint64(reader.ReadU8()))
+	case reflect.Uint16:
+		v.SetUint(uint64(reader.ReadU16()))
+	case reflect.Uint32:
+		v.SetUint(uint64(reader.ReadU32()))
+	case reflect.Uint, reflect.Uint64:
+		v.SetUint(reader.ReadU64())
+	case reflect.Float32:
+		v.SetFloat(float64(reader.ReadF32()))
+	case reflect.Float64:
+		v.SetFloat(reader.ReadF64())
+	case reflect.String:
+		v.SetString(reader.ReadString())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(reader.ReadBytes())
+			return reader.Err()
+		}
+		length := int(reader.ReadArrayLength())
+		if reader.err != nil {
+			return reader.Err()
+		}
+		return unmarshalSlice(reader, v, length)
+	case reflect.Array:
+		length := int(reader.ReadArrayLength())
+		if reader.err != nil {
+			return reader.Err()
+		}
+		if length != v.Len() {
+			return unmarshalError(reader, "Array length mismatch: expected "+
+				strconv.Itoa(v.Len())+", found "+strconv.Itoa(length))
+		}
+		return unmarshalArray(reader, v, length)
+	case reflect.Map:
+		return unmarshalMap(reader, v)
+	case reflect.Struct:
+		if v.Type() == bigIntType {
+			if value := reader.ReadBigInt(); value != nil {
+				v.Set(reflect.ValueOf(value).Elem())
+			}
+			return reader.Err()
+		}
+		if v.Type() == bigNumberType {
+			if value := reader.ReadBigNumber(); value != nil {
+				v.Set(reflect.ValueOf(value).Elem())
+			}
+			return reader.Err()
+		}
+		return unmarshalStruct(reader, v)
+	default:
+		return unmarshalError(reader, "Unsupported type '"+typeName(v.Type())+"'")
+	}
+	return reader.Err()
+}
+
+// nilable reports whether Strict accepts nil for a value of type t: optional
+// values are pointers or interfaces, and slices take the nil that empty bytes
+// and arrays are written as.
+func nilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+func unmarshalArray(reader *ReadDecoder, v reflect.Value, length int) error {
+	if !reader.enter() {
+		return reader.Err()
+	}
+	defer reader.leave()
+	for i := 0; i < length; i++ {
+		reader.context.Push(strconv.Itoa(i), typeName(v.Type().Elem()), "reading array item")
+		if err := unmarshalValue(reader, v.Index(i)); err != nil {
+			return err
+		}
+		reader.context.Pop()
+	}
+	return nil
+}
+
+// unmarshalSlice grows the slice as items are decoded, so a hostile length
+// can't allocate more than the input could fill.
+func unmarshalSlice(reader *ReadDecoder, v reflect.Value, length int) error {
+	if !reader.enter() {
+		return reader.Err()
+	}
+	defer reader.leave()
+	t := v.Type()
+	slice := reflect.MakeSlice(t, 0, preallocation(reader, uint32(length), t.Elem().Size()))
+	zero := reflect.Zero(t.Elem())
+	for i := 0; i < length; i++ {
+		reader.context.Push(strconv.Itoa(i), typeName(t.Elem()), "reading array item")
+		slice = reflect.Append(slice, zero)
+		if err := unmarshalValue(reader, slice.Index(i)); err != nil {
+			return err
+		}
+		reader.context.Pop()
+	}
+	v.Set(slice)
+	return nil
+}
+
+// preallocation bounds the capacity for size elements of elemSize bytes so
+// that it takes no more memory than the buffered input.
+func preallocation(reader *ReadDecoder, size uint32, elemSize uintptr) int {
+	n := reader.capacity(size)
+	if elemSize > 1 && uint64(n)*uint64(elemSize) > uint64(reader.view.Remaining()) {
+		n = int(uint64(reader.view.Remaining()) / uint64(elemSize))
+	}
+	return n
+}
+
+func unmarshalMap(reader *ReadDecoder, v reflect.Value) error {
+	if isExt(reader.peekFormat()) {
+		ln := reader.readExtGenericMapLength()
+		if reader.err != nil {
+			return reader.Err()
+		}
+		start, f, offset := reader.view.Offset(), reader.format, reader.offset
+		if err := unmarshalMapEntries(reader, v); err != nil {
+			return err
+		}
+		if reader.view.Offset()-start != int(ln) {
+			reader.fail("GenericMap extension length mismatch: header says "+strconv.FormatUint(uint64(ln), 10)+
+				" byte(s), map has "+strconv.Itoa(reader.view.Offset()-start), format.EXT32, f, offset)
+		}
+		return reader.Err()
+	}
+	return unmarshalMapEntries(reader, v)
+}
+
+func unmarshalMapEntries(reader *ReadDecoder, v reflect.Value) error {
+	length := reader.ReadMapLength()
+	if !reader.enter() {
+		return reader.Err()
+	}
+	defer reader.leave()
+	t := v.Type()
+	v.Set(reflect.MakeMapWithSize(t, preallocation(reader, length, t.Key().Size()+t.Elem().Size())))
+	for i := uint32(0); i < length; i++ {
+		key := reflect.New(t.Key()).Elem()
+		if err := unmarshalValue(reader, key); err != nil {
+			return err
+		}
+		value := reflect.New(t.Elem()).Elem()
+		if err := unmarshalValue(reader, value); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, value)
+	}
+	return reader.Err()
+}
+
+func unmarshalStruct(reader *ReadDecoder, v reflect.Value) error {
+	fields := structFields(v.Type())
+	numFields := reader.ReadMapLength()
+	if !reader.enter() {
+		return reader.Err()
+	}
+	defer reader.leave()
+
+	for i := numFields; i > 0; i-- {
+		name := reader.ReadString()
+		if reader.err != nil {
+			return reader.Err()
+		}
+
+		reader.Context().Push(name, "unknown", searchingInfo)
+		index := -1
+		for j := range fields {
+			if fields[j].name == name {
+				index = fields[j].index
+				break
+			}
+		}
+		if index < 0 {
+			reader.Skip()
+			reader.Context().Pop()
+			continue
+		}
+
+		field := v.Field(index)
+		reader.Context().Push(name, typeName(field.Type()), "type found, reading property")
+		if err := unmarshalValue(reader, field); err != nil {
+			return err
+		}
+		reader.Context().Pop()
+		reader.Context().Pop()
+	}
+	return reader.Err()
+}
@@ -0,0 +1,96 @@
+package msgpack
+
+import (
+	"reflect"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/valyala/fastjson"
+)
+
+// Ext is an extension value whose type is not registered in
+// DefaultExtRegistry.
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+// writeValue writes a dynamic value as produced by ReadDecoder.ReadValue.
+// Values of other types are written like Marshal does.
+func writeValue(writer Write, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		writer.WriteNil()
+	case bool:
+		writer.WriteBool(v)
+	case int:
+		writer.WriteI64(int64(v))
+	case int8:
+		writer.WriteI8(v)
+	case int16:
+		writer.WriteI16(v)
+	case int32:
+		writer.WriteI32(v)
+	case int64:
+		writer.WriteI64(v)
+	case uint:
+		writer.WriteU64(uint64(v))
+	case uint8:
+		writer.WriteU8(v)
+	case uint16:
+		writer.WriteU16(v)
+	case uint32:
+		writer.WriteU32(v)
+	case uint64:
+		writer.WriteU64(v)
+	case float32:
+		writer.WriteFloat32(v)
+	case float64:
+		writer.WriteFloat64(v)
+	case string:
+		writer.WriteString(v)
+	case []byte:
+		if len(v) == 0 {
+			writer.WriteBytesLength(0)
+			return
+		}
+		writer.WriteBytes(v)
+	case []interface{}:
+		writer.WriteArrayLength(uint32(len(v)))
+		for i := range v {
+			writeValue(writer, v[i])
+		}
+	case map[string]interface{}:
+		entries := make(map[interface{}]interface{}, len(v))
+		for key, item := range v {
+			entries[key] = item
+		}
+		writer.WriteMap(entries, writeValueEntry)
+	case map[interface{}]interface{}:
+		writer.WriteMap(v, writeValueEntry)
+	case Ext:
+		writer.WriteExt(v.Type, v.Data)
+	case *big.Int:
+		writer.WriteBigInt(v)
+	case *big.Number:
+		writer.WriteBigNumber(v)
+	case *fastjson.Value:
+		writer.WriteJson(v)
+	default:
+		if err := marshalValue(writer, reflect.ValueOf(value)); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func writeValueEntry(writer Write, key interface{}, value interface{}) {
+	writeValue(writer, key)
+	writeValue(writer, value)
+}
+
+func isHashable(value interface{}) bool {
+	switch value.(type) {
+	case nil, bool, int64, uint64, float64, string:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package msgpack
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestReadWriteValue(t *testing.T) {
+	cases := []struct {
+		name  string
+		value interface{}
+	}{
+		{"nil", nil},
+		{"bool", true},
+		{"positive fixed int", int64(1)},
+		{"negative fixed int", int64(-1)},
+		{"int64", int64(math.MinInt64)},
+		{"uint64", uint64(math.MaxUint64)},
+		{"float64", 0.5},
+		{"string", "value"},
+		{"bytes", []byte{1, 2, 3}},
+		{"empty bytes", []byte{}},
+		{"empty array", []interface{}{}},
+		{"array", []interface{}{int64(1), "two", nil, []interface{}{false}}},
+		{"string map", map[string]interface{}{"a": int64(1), "b": map[string]interface{}{}}},
+		{"mixed map", map[interface{}]interface{}{"a": int64(1), int64(2): "b", nil: true}},
+		{"ext", Ext{Type: 42, Data: []byte{1, 2, 3}}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			sizer := NewWriteSizer(NewContext(""))
+			sizer.WriteValue(tcase.value)
+			writer := NewWriteEncoder(NewContext(""))
+			writer.WriteValue(tcase.value)
+			if int(sizer.Length()) != len(writer.Buffer()) {
+				t.Errorf("Bad length, got: %d, want: %d", sizer.Length(), len(writer.Buffer()))
+			}
+
+			reader := NewReadDecoderWithOptions(NewContext(""), writer.Buffer(), ReadOptions{})
+			actual, err := reader.ReadValue()
+			if err != nil {
+				t.Fatalf("ReadValue error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tcase.value) {
+				t.Errorf("Bad value, got: %#v, want: %#v", actual, tcase.value)
+			}
+		})
+	}
+}
+
+func TestReadValueFormats(t *testing.T) {
+	cases := []struct {
+		name  string
+		bytes []byte
+		value interface{}
+	}{
+		{"uint8", []byte{0xcc, 0xff}, uint64(255)},
+		{"int8", []byte{0xd0, 0x80}, int64(-128)},
+		{"float32", []byte{0xca, 0x3f, 0x00, 0x00, 0x00}, float64(0.5)},
+		{"generic map", []byte{0xc7, 4, 1, 0x81, 0xa1, 0x61, 1}, map[string]interface{}{"a": int64(1)}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewReadDecoderWithOptions(NewContext(""), tcase.bytes, ReadOptions{})
+			actual, err := reader.ReadValue()
+			if err != nil {
+				t.Fatalf("ReadValue error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tcase.value) {
+				t.Errorf("Bad value, got: %#v, want: %#v", actual, tcase.value)
+			}
+		})
+	}
+}
+
+func TestReadValueErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		bytes []byte
+	}{
+		{"empty", []byte{}},
+		{"reserved", []byte{0xc1}},
+		{"truncated array", []byte{0x92, 0x01}},
+		{"array key", []byte{0x81, 0x90, 0x01}},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			reader := NewReadDecoderWithOptions(NewContext(""), tcase.bytes, ReadOptions{})
+			if _, err := reader.ReadValue(); err == nil {
+				t.Errorf("Expected error")
+			}
+		})
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	type payload struct {
+		Data interface{}
+	}
+	expected := payload{Data: map[string]interface{}{"list": []interface{}{int64(1), "a"}}}
+
+	data, err := Marshal(expected)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	var actual payload
+	if err := Unmarshal(data, &actual); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Bad value, got: %#v, want: %#v", actual, expected)
+	}
+}
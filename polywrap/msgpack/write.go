@@ -57,6 +57,9 @@ type Write interface {
 	WriteBigInt(value *big.Int)
 	WriteOptionalBigInt(value container.Option)
 
+	WriteBigNumber(value *big.Number)
+	WriteOptionalBigNumber(value container.Option)
+
 	WriteArrayLength(length uint32)
 	WriteArray(value []interface{}, fn func(encoder Write, item interface{}))
 	WriteOptionalArray(value container.Option, fn func(encoder Write, item interface{}))
@@ -64,4 +67,10 @@ type Write interface {
 	WriteMapLength(length uint32)
 	WriteMap(value map[interface{}]interface{}, fn func(encoder Write, key interface{}, value interface{}))
 	WriteOptionalMap(value container.Option, fn func(encoder Write, key interface{}, value interface{}))
+
+	WriteExt(typ int8, data []byte)
+	WriteExtGenericMap(value map[interface{}]interface{}, fn func(encoder Write, key interface{}, value interface{}))
+	WriteOptionalExtGenericMap(value container.Option, fn func(encoder Write, key interface{}, value interface{}))
+
+	WriteValue(value interface{})
 }
@@ -1,7 +1,9 @@
 package msgpack
 
 import (
+	"bytes"
 	"math"
+	"sort"
 
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
 	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
@@ -9,13 +11,48 @@ import (
 	"github.com/valyala/fastjson"
 )
 
+// WriteOptions configures a WriteEncoder.
+type WriteOptions struct {
+	// Size preallocates the buffer, usually to the Length of a WriteSizer run
+	// over the same value.
+	Size int32
+	// Canonical makes the encoding deterministic: map entries are sorted by
+	// their encoded keys, non-negative integers use the unsigned formats and
+	// integer and length headers always take their smallest form.
+	Canonical bool
+}
+
 type WriteEncoder struct {
 	context *Context
 	view    *DataView
+	options WriteOptions
 }
 
 func NewWriteEncoder(context *Context) *WriteEncoder {
-	return &WriteEncoder{context: context, view: NewDataView(context)}
+	return newWriteEncoder(context, NewDataView(context), WriteOptions{})
+}
+
+// NewWriteEncoderWithSize returns an encoder whose buffer is preallocated to
+// size bytes, usually the Length of a WriteSizer run over the same value.
+func NewWriteEncoderWithSize(context *Context, size int32) *WriteEncoder {
+	return NewWriteEncoderWithOptions(context, WriteOptions{Size: size})
+}
+
+func NewWriteEncoderWithOptions(context *Context, options WriteOptions) *WriteEncoder {
+	return newWriteEncoder(context, NewDataViewWithSize(context, options.Size), options)
+}
+
+func newWriteEncoder(context *Context, view *DataView, options WriteOptions) *WriteEncoder {
+	context.bind(view.Written)
+	return &WriteEncoder{context: context, view: view, options: options}
+}
+
+// Reset empties the encoder and its context stack so that it can encode a new
+// value into the same buffer. Slices returned by Buffer before are
+// overwritten by the following writes.
+func (we *WriteEncoder) Reset() {
+	we.context.Reset(we.context.description)
+	we.view.Reset(we.view.buf[:0])
 }
 
 func (we *WriteEncoder) Context() *Context {
@@ -23,7 +60,7 @@ func (we *WriteEncoder) Context() *Context {
 }
 
 func (we *WriteEncoder) Buffer() []byte {
-	return we.view.buf.Bytes()
+	return we.view.Bytes()
 }
 
 func (we *WriteEncoder) WriteNil() {
@@ -99,7 +136,9 @@ func (we *WriteEncoder) WriteOptionalI32(value container.Option) {
 }
 
 func (we *WriteEncoder) WriteI64(value int64) {
-	if value >= 0 && value < 1<<7 {
+	if we.options.Canonical && value >= 0 {
+		we.WriteU64(uint64(value))
+	} else if value >= 0 && value < 1<<7 {
 		// positive fixed int
 		we.view.WriteInt8(int8(value))
 	} else if value < 0 && value >= -(1<<5) {
@@ -246,10 +285,11 @@ func (we *WriteEncoder) WriteOptionalFloat64(value container.Option) {
 }
 
 func (we *WriteEncoder) WriteBytesLength(length uint32) {
-	if length < math.MaxUint8 {
+	// the lenient bounds are kept for compatibility with existing encodings
+	if length < math.MaxUint8 || (we.options.Canonical && length == math.MaxUint8) {
 		we.view.WriteFormat(format.BIN8)
 		we.view.WriteUint8(uint8(length))
-	} else if length < math.MaxUint16 {
+	} else if length < math.MaxUint16 || (we.options.Canonical && length == math.MaxUint16) {
 		we.view.WriteFormat(format.BIN16)
 		we.view.WriteUint16(uint16(length))
 	} else {
@@ -351,6 +391,26 @@ func (we *WriteEncoder) WriteOptionalBigInt(value container.Option) {
 	we.WriteBigInt(v)
 }
 
+func (we *WriteEncoder) WriteBigNumber(value *big.Number) {
+	if value == nil {
+		we.WriteNil()
+		return
+	}
+	we.WriteString(value.String())
+}
+
+func (we *WriteEncoder) WriteOptionalBigNumber(value container.Option) {
+	if value.IsNone() {
+		we.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(*big.Number)
+	if !ok {
+		panic(we.context.PrintWithContext("Argument must be of type '*big.Number'"))
+	}
+	we.WriteBigNumber(v)
+}
+
 func (we *WriteEncoder) WriteArrayLength(length uint32) {
 	if length < 16 {
 		we.view.WriteUint8(uint8(length) | uint8(format.FIXARRAY))
@@ -400,8 +460,36 @@ func (we *WriteEncoder) WriteMapLength(length uint32) {
 
 func (we *WriteEncoder) WriteMap(value map[interface{}]interface{}, fn func(encoder Write, key interface{}, value interface{})) {
 	we.WriteMapLength(uint32(len(value)))
-	for key := range value {
-		fn(we, key, value[key])
+	if !we.options.Canonical {
+		for key, item := range value {
+			fn(we, key, item)
+		}
+		return
+	}
+
+	// An encoded key is never the prefix of another one, so ordering the
+	// encoded entries orders them by their keys.
+	entries := make([][]byte, 0, len(value))
+	for key, item := range value {
+		entry := NewWriteEncoderWithOptions(we.context, WriteOptions{Canonical: true})
+		fn(entry, key, item)
+		entries = append(entries, entry.Buffer())
+	}
+	we.writeSortedEntries(entries)
+}
+
+func (we *WriteEncoder) canonical() bool {
+	return we.options.Canonical
+}
+
+// writeSortedEntries writes map entries that were encoded separately in the
+// order of their encoding.
+func (we *WriteEncoder) writeSortedEntries(entries [][]byte) {
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i], entries[j]) < 0
+	})
+	for i := range entries {
+		we.view.WriteBytes(entries[i])
 	}
 }
 
@@ -416,3 +504,78 @@ func (we *WriteEncoder) WriteOptionalMap(value container.Option, fn func(encoder
 	}
 	we.WriteMap(v, fn)
 }
+
+func (we *WriteEncoder) writeExtLength(length uint32) {
+	switch length {
+	case 1:
+		we.view.WriteFormat(format.FIXEXT1)
+	case 2:
+		we.view.WriteFormat(format.FIXEXT2)
+	case 4:
+		we.view.WriteFormat(format.FIXEXT4)
+	case 8:
+		we.view.WriteFormat(format.FIXEXT8)
+	case 16:
+		we.view.WriteFormat(format.FIXEXT16)
+	default:
+		if length <= math.MaxUint8 {
+			we.view.WriteFormat(format.EXT8)
+			we.view.WriteUint8(uint8(length))
+		} else if length <= math.MaxUint16 {
+			we.view.WriteFormat(format.EXT16)
+			we.view.WriteUint16(uint16(length))
+		} else {
+			we.view.WriteFormat(format.EXT32)
+			we.view.WriteUint32(length)
+		}
+	}
+}
+
+func (we *WriteEncoder) WriteExt(typ int8, data []byte) {
+	we.writeExtLength(uint32(len(data)))
+	we.view.WriteInt8(typ)
+	we.view.WriteBytes(data)
+}
+
+// WriteExtGenericMap writes value as a Polywrap GenericMap extension. Unlike
+// WriteExt it always uses an EXT8/16/32 header, as the Rust and AssemblyScript
+// encoders do.
+func (we *WriteEncoder) WriteExtGenericMap(value map[interface{}]interface{}, fn func(encoder Write, key interface{}, value interface{})) {
+	sizer := NewWriteSizerWithOptions(we.context, we.options)
+	sizer.WriteMap(value, fn)
+
+	we.writeExtGenericMapLength(uint32(sizer.Length()))
+	we.WriteMap(value, fn)
+}
+
+func (we *WriteEncoder) writeExtGenericMapLength(length uint32) {
+	if length <= math.MaxUint8 {
+		we.view.WriteFormat(format.EXT8)
+		we.view.WriteUint8(uint8(length))
+	} else if length <= math.MaxUint16 {
+		we.view.WriteFormat(format.EXT16)
+		we.view.WriteUint16(uint16(length))
+	} else {
+		we.view.WriteFormat(format.EXT32)
+		we.view.WriteUint32(length)
+	}
+	we.view.WriteInt8(ExtGenericMap)
+}
+
+func (we *WriteEncoder) WriteOptionalExtGenericMap(value container.Option, fn func(encoder Write, key interface{}, value interface{})) {
+	if value.IsNone() {
+		we.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(map[interface{}]interface{})
+	if !ok {
+		panic(we.context.PrintWithContext("Argument must be of type 'map[interface{}]interface{}'"))
+	}
+	we.WriteExtGenericMap(v, fn)
+}
+
+// WriteValue writes a dynamic value such as the ones returned by
+// ReadDecoder.ReadValue. Values of other types are written like Marshal does.
+func (we *WriteEncoder) WriteValue(value interface{}) {
+	writeValue(we, value)
+}
@@ -99,6 +99,14 @@ func runWriteCases(t *testing.T, cases []writecase) {
 				}
 			case "bigint?":
 				writer.WriteOptionalBigInt(tcase.value.(container.Option))
+			case "bignumber":
+				if tcase.value == nil {
+					writer.WriteBigNumber(nil)
+				} else {
+					writer.WriteBigNumber(tcase.value.(*big.Number))
+				}
+			case "bignumber?":
+				writer.WriteOptionalBigNumber(tcase.value.(container.Option))
 			case "json":
 				if tcase.value == nil {
 					writer.WriteJson(nil)
@@ -534,6 +542,16 @@ func TestWriteBigInt(t *testing.T) {
 	})
 }
 
+func TestWriteBigNumber(t *testing.T) {
+	runWriteCases(t, []writecase{
+		{name: "nil", format: "bignumber", value: nil, bytes: []byte{192}},
+		{name: "fraction", format: "bignumber", value: big.NewNumber(-1256, -2), bytes: []byte{166, 45, 49, 50, 46, 53, 54}},
+		{name: "exponential", format: "bignumber", value: big.NewNumber(1, 21), bytes: []byte{165, 49, 101, 43, 50, 49}},
+		{name: "optional nil", format: "bignumber?", value: container.None(), bytes: []byte{192}},
+		{name: "optional fraction", format: "bignumber?", value: container.Some(big.NewNumber(-1256, -2)), bytes: []byte{166, 45, 49, 50, 46, 53, 54}},
+	})
+}
+
 func TestWriteJSON(t *testing.T) {
 	runWriteCases(t, []writecase{
 		{name: "nil", format: "json", value: nil, bytes: []byte{192}},
@@ -583,6 +601,25 @@ func TestWriteMap(t *testing.T) {
 				encoder.WriteString(v)
 			},
 		},
+		{
+			name:   "map[float64]int8 with NaN keys",
+			format: "map",
+			value: map[interface{}]interface{}{
+				math.NaN(): int8(1),
+				math.NaN(): int8(2),
+			},
+			prefix: []byte{130},
+			parts: [][]byte{
+				{203, 127, 248, 0, 0, 0, 0, 0, 1, 1},
+				{203, 127, 248, 0, 0, 0, 0, 0, 1, 2},
+			},
+			fn2: func(encoder Write, key interface{}, value interface{}) {
+				k := key.(float64)
+				encoder.WriteFloat64(k)
+				v := value.(int8)
+				encoder.WriteI8(v)
+			},
+		},
 		{
 			name:   "optional nil",
 			format: "map?",
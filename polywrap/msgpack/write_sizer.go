@@ -1,20 +1,48 @@
 package msgpack
 
-import "math"
+import (
+	"math"
 
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+	"github.com/valyala/fastjson"
+)
+
+// WriteSizer computes the exact number of bytes a WriteEncoder would produce
+// for the same sequence of writes, so that the encoder buffer can be
+// allocated once with NewWriteEncoderWithSize.
 type WriteSizer struct {
 	length  int32
 	context *Context
+	options WriteOptions
 }
 
 func NewWriteSizer(context *Context) *WriteSizer {
-	return &WriteSizer{length: 0, context: context}
+	return NewWriteSizerWithOptions(context, WriteOptions{})
+}
+
+// NewWriteSizerWithOptions sizes the output of an encoder created with the
+// same options. Size is ignored.
+func NewWriteSizerWithOptions(context *Context, options WriteOptions) *WriteSizer {
+	ws := &WriteSizer{length: 0, context: context, options: options}
+	context.bind(func() int { return int(ws.length) })
+	return ws
+}
+
+// Reset sets the length back to zero and empties the context stack.
+func (ws *WriteSizer) Reset() {
+	ws.context.Reset(ws.context.description)
+	ws.length = 0
 }
 
 func (ws *WriteSizer) Context() *Context {
 	return ws.context
 }
 
+func (ws *WriteSizer) Length() int32 {
+	return ws.length
+}
+
 func (ws *WriteSizer) WriteNil() {
 	ws.length++
 }
@@ -22,9 +50,234 @@ func (ws *WriteSizer) WriteNil() {
 func (ws *WriteSizer) WriteBool(_ bool) {
 	ws.length++
 }
-func (ws *WriteSizer) WriteI8(_ int8) {
-	ws.length++
+
+func (ws *WriteSizer) WriteOptionalBool(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(bool)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'bool'"))
+	}
+	ws.WriteBool(v)
+}
+
+func (ws *WriteSizer) WriteI8(value int8) {
+	ws.WriteI64(int64(value))
+}
+
+func (ws *WriteSizer) WriteOptionalI8(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(int8)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'int8'"))
+	}
+	ws.WriteI8(v)
+}
+
+func (ws *WriteSizer) WriteI16(value int16) {
+	ws.WriteI64(int64(value))
+}
+
+func (ws *WriteSizer) WriteOptionalI16(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(int16)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'int16'"))
+	}
+	ws.WriteI16(v)
+}
+
+func (ws *WriteSizer) WriteI32(value int32) {
+	ws.WriteI64(int64(value))
+}
+
+func (ws *WriteSizer) WriteOptionalI32(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(int32)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'int32'"))
+	}
+	ws.WriteI32(v)
+}
+
+func (ws *WriteSizer) WriteI64(value int64) {
+	if ws.options.Canonical && value >= 0 {
+		ws.WriteU64(uint64(value))
+	} else if value >= -(1<<5) && value < 1<<7 {
+		// positive or negative fixed int
+		ws.length++
+	} else if value <= math.MaxInt8 && value >= math.MinInt8 {
+		ws.length += 2
+	} else if value <= math.MaxInt16 && value >= math.MinInt16 {
+		ws.length += 3
+	} else if value <= math.MaxInt32 && value >= math.MinInt32 {
+		ws.length += 5
+	} else {
+		ws.length += 9
+	}
+}
+
+func (ws *WriteSizer) WriteOptionalI64(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(int64)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'int64'"))
+	}
+	ws.WriteI64(v)
 }
+
+func (ws *WriteSizer) WriteU8(value uint8) {
+	ws.WriteU64(uint64(value))
+}
+
+func (ws *WriteSizer) WriteOptionalU8(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(uint8)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'uint8'"))
+	}
+	ws.WriteU8(v)
+}
+
+func (ws *WriteSizer) WriteU16(value uint16) {
+	ws.WriteU64(uint64(value))
+}
+
+func (ws *WriteSizer) WriteOptionalU16(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(uint16)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'uint16'"))
+	}
+	ws.WriteU16(v)
+}
+
+func (ws *WriteSizer) WriteU32(value uint32) {
+	ws.WriteU64(uint64(value))
+}
+
+func (ws *WriteSizer) WriteOptionalU32(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(uint32)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'uint32'"))
+	}
+	ws.WriteU32(v)
+}
+
+func (ws *WriteSizer) WriteU64(value uint64) {
+	if value < 1<<7 {
+		// positive fixed int
+		ws.length++
+	} else if value <= math.MaxUint8 {
+		ws.length += 2
+	} else if value <= math.MaxUint16 {
+		ws.length += 3
+	} else if value <= math.MaxUint32 {
+		ws.length += 5
+	} else {
+		ws.length += 9
+	}
+}
+
+func (ws *WriteSizer) WriteOptionalU64(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(uint64)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'uint64'"))
+	}
+	ws.WriteU64(v)
+}
+
+func (ws *WriteSizer) WriteFloat32(_ float32) {
+	ws.length += 5
+}
+
+func (ws *WriteSizer) WriteOptionalFloat32(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(float32)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'float32'"))
+	}
+	ws.WriteFloat32(v)
+}
+
+func (ws *WriteSizer) WriteFloat64(_ float64) {
+	ws.length += 9
+}
+
+func (ws *WriteSizer) WriteOptionalFloat64(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(float64)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'float64'"))
+	}
+	ws.WriteFloat64(v)
+}
+
+func (ws *WriteSizer) WriteBytesLength(length uint32) {
+	if length < math.MaxUint8 || (ws.options.Canonical && length == math.MaxUint8) {
+		ws.length += 2
+	} else if length < math.MaxUint16 || (ws.options.Canonical && length == math.MaxUint16) {
+		ws.length += 3
+	} else {
+		ws.length += 5
+	}
+}
+
+func (ws *WriteSizer) WriteBytes(value []byte) {
+	if len(value) == 0 {
+		ws.WriteNil()
+		return
+	}
+	ws.WriteBytesLength(uint32(len(value)))
+	ws.length += int32(len(value))
+}
+
+func (ws *WriteSizer) WriteOptionalBytes(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().([]byte)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type '[]byte'"))
+	}
+	ws.WriteBytes(v)
+}
+
 func (ws *WriteSizer) WriteStringLength(length uint32) {
 	if length < 32 {
 		ws.length++
@@ -36,11 +289,117 @@ func (ws *WriteSizer) WriteStringLength(length uint32) {
 		ws.length += 5
 	}
 }
+
 func (ws *WriteSizer) WriteString(value string) {
 	ws.WriteStringLength(uint32(len(value)))
 	ws.length += int32(len(value))
 }
 
+func (ws *WriteSizer) WriteOptionalString(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(string)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'string'"))
+	}
+	ws.WriteString(v)
+}
+
+func (ws *WriteSizer) WriteJson(value *fastjson.Value) {
+	if value == nil {
+		ws.WriteNil()
+		return
+	}
+	ws.WriteString(value.String())
+}
+
+func (ws *WriteSizer) WriteOptionalJson(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(*fastjson.Value)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type '*fastjson.Value'"))
+	}
+	ws.WriteJson(v)
+}
+
+func (ws *WriteSizer) WriteBigInt(value *big.Int) {
+	if value == nil {
+		ws.WriteNil()
+		return
+	}
+	ws.WriteString(value.String())
+}
+
+func (ws *WriteSizer) WriteOptionalBigInt(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(*big.Int)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type '*big.Int'"))
+	}
+	ws.WriteBigInt(v)
+}
+
+func (ws *WriteSizer) WriteBigNumber(value *big.Number) {
+	if value == nil {
+		ws.WriteNil()
+		return
+	}
+	ws.WriteString(value.String())
+}
+
+func (ws *WriteSizer) WriteOptionalBigNumber(value container.Option) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(*big.Number)
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type '*big.Number'"))
+	}
+	ws.WriteBigNumber(v)
+}
+
+func (ws *WriteSizer) WriteArrayLength(length uint32) {
+	if length < 16 {
+		ws.length++
+	} else if length <= math.MaxUint16 {
+		ws.length += 3
+	} else {
+		ws.length += 5
+	}
+}
+
+func (ws *WriteSizer) WriteArray(value []interface{}, fn func(encoder Write, item interface{})) {
+	if len(value) == 0 {
+		ws.WriteNil()
+		return
+	}
+	ws.WriteArrayLength(uint32(len(value)))
+	for i := range value {
+		fn(ws, value[i])
+	}
+}
+
+func (ws *WriteSizer) WriteOptionalArray(value container.Option, fn func(encoder Write, item interface{})) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().([]interface{})
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type '[]interface{}'"))
+	}
+	ws.WriteArray(v, fn)
+}
+
 func (ws *WriteSizer) WriteMapLength(length uint32) {
 	if length < 16 {
 		ws.length++
@@ -51,6 +410,73 @@ func (ws *WriteSizer) WriteMapLength(length uint32) {
 	}
 }
 
-func (ws *WriteSizer) Length() int32 {
-	return ws.length
+func (ws *WriteSizer) WriteMap(value map[interface{}]interface{}, fn func(encoder Write, key interface{}, value interface{})) {
+	ws.WriteMapLength(uint32(len(value)))
+	for key, item := range value {
+		fn(ws, key, item)
+	}
+}
+
+func (ws *WriteSizer) WriteOptionalMap(value container.Option, fn func(encoder Write, key interface{}, value interface{})) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(map[interface{}]interface{})
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'map[interface{}]interface{}'"))
+	}
+	ws.WriteMap(v, fn)
+}
+
+func (ws *WriteSizer) writeExtLength(length uint32) {
+	switch length {
+	case 1, 2, 4, 8, 16:
+		ws.length++
+	default:
+		if length <= math.MaxUint8 {
+			ws.length += 2
+		} else if length <= math.MaxUint16 {
+			ws.length += 3
+		} else {
+			ws.length += 5
+		}
+	}
+}
+
+func (ws *WriteSizer) WriteExt(_ int8, data []byte) {
+	ws.writeExtLength(uint32(len(data)))
+	ws.length += 1 + int32(len(data))
+}
+
+func (ws *WriteSizer) WriteExtGenericMap(value map[interface{}]interface{}, fn func(encoder Write, key interface{}, value interface{})) {
+	sizer := NewWriteSizerWithOptions(ws.context, ws.options)
+	sizer.WriteMap(value, fn)
+
+	if sizer.length <= math.MaxUint8 {
+		ws.length += 2
+	} else if sizer.length <= math.MaxUint16 {
+		ws.length += 3
+	} else {
+		ws.length += 5
+	}
+	ws.length += 1 + sizer.length
+}
+
+func (ws *WriteSizer) WriteOptionalExtGenericMap(value container.Option, fn func(encoder Write, key interface{}, value interface{})) {
+	if value.IsNone() {
+		ws.WriteNil()
+		return
+	}
+	v, ok := value.MustGet().(map[interface{}]interface{})
+	if !ok {
+		panic(ws.context.PrintWithContext("Argument must be of type 'map[interface{}]interface{}'"))
+	}
+	ws.WriteExtGenericMap(v, fn)
+}
+
+// WriteValue writes a dynamic value such as the ones returned by
+// ReadDecoder.ReadValue. Values of other types are written like Marshal does.
+func (ws *WriteSizer) WriteValue(value interface{}) {
+	writeValue(ws, value)
 }
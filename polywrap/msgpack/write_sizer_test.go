@@ -0,0 +1,90 @@
+package msgpack
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/big"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack/container"
+	"github.com/valyala/fastjson"
+)
+
+var _ Write = (*WriteSizer)(nil)
+
+func TestWriteSizer(t *testing.T) {
+	writeI64 := func(encoder Write, item interface{}) {
+		encoder.WriteI64(item.(int64))
+	}
+	writeStrings := func(encoder Write, key interface{}, value interface{}) {
+		encoder.WriteString(key.(string))
+		encoder.WriteString(value.(string))
+	}
+
+	cases := []struct {
+		name string
+		fn   func(writer Write)
+	}{
+		{"nil", func(w Write) { w.WriteNil() }},
+		{"bool", func(w Write) { w.WriteBool(true) }},
+		{"optional bool", func(w Write) { w.WriteOptionalBool(container.Some(false)) }},
+		{"negative fixed int", func(w Write) { w.WriteI8(-32) }},
+		{"int8", func(w Write) { w.WriteI8(-33) }},
+		{"int16", func(w Write) { w.WriteI16(128) }},
+		{"int32", func(w Write) { w.WriteI32(math.MinInt32) }},
+		{"int64", func(w Write) { w.WriteI64(math.MaxInt64) }},
+		{"optional int32", func(w Write) { w.WriteOptionalI32(container.Some(int32(-100500))) }},
+		{"fixed uint", func(w Write) { w.WriteU8(127) }},
+		{"uint8", func(w Write) { w.WriteU8(255) }},
+		{"uint16", func(w Write) { w.WriteU16(256) }},
+		{"uint32", func(w Write) { w.WriteU32(math.MaxUint32) }},
+		{"uint64", func(w Write) { w.WriteU64(math.MaxUint64) }},
+		{"optional uint64", func(w Write) { w.WriteOptionalU64(container.None()) }},
+		{"float32", func(w Write) { w.WriteFloat32(0.5) }},
+		{"float64", func(w Write) { w.WriteFloat64(0.5) }},
+		{"empty bytes", func(w Write) { w.WriteBytes(nil) }},
+		{"bytes8", func(w Write) { w.WriteBytes(make([]byte, 254)) }},
+		{"bytes16", func(w Write) { w.WriteBytes(make([]byte, 255)) }},
+		{"bytes32", func(w Write) { w.WriteBytes(make([]byte, math.MaxUint16)) }},
+		{"fixed string", func(w Write) { w.WriteString("value") }},
+		{"string8", func(w Write) { w.WriteString(strings.Repeat("a", 32)) }},
+		{"string16", func(w Write) { w.WriteString(strings.Repeat("a", 256)) }},
+		{"string32", func(w Write) { w.WriteString(strings.Repeat("a", math.MaxUint16+1)) }},
+		{"optional string", func(w Write) { w.WriteOptionalString(container.Some("value")) }},
+		{"json", func(w Write) { w.WriteJson(fastjson.MustParse(`{"key":[1,2,3]}`)) }},
+		{"bigint", func(w Write) { w.WriteBigInt(big.NewInt(-100500)) }},
+		{"bignumber", func(w Write) { w.WriteBigNumber(big.NewNumber(-100500, -3)) }},
+		{"empty array", func(w Write) { w.WriteArray(nil, writeI64) }},
+		{"array", func(w Write) { w.WriteArray([]interface{}{int64(1), int64(1000), int64(-1 << 40)}, writeI64) }},
+		{"array16", func(w Write) { w.WriteArrayLength(16) }},
+		{"array32", func(w Write) { w.WriteArrayLength(math.MaxUint16 + 1) }},
+		{"map", func(w Write) {
+			w.WriteMap(map[interface{}]interface{}{"key1": "value1", "key2": "value2"}, writeStrings)
+		}},
+		{"optional map", func(w Write) { w.WriteOptionalMap(container.None(), writeStrings) }},
+		{"map16", func(w Write) { w.WriteMapLength(16) }},
+		{"map32", func(w Write) { w.WriteMapLength(math.MaxUint16 + 1) }},
+		{"fixext", func(w Write) { w.WriteExt(5, make([]byte, 16)) }},
+		{"ext8", func(w Write) { w.WriteExt(5, make([]byte, 3)) }},
+		{"ext16", func(w Write) { w.WriteExt(5, make([]byte, 256)) }},
+		{"ext32", func(w Write) { w.WriteExt(5, make([]byte, math.MaxUint16+1)) }},
+	}
+
+	for i := range cases {
+		tcase := cases[i]
+		t.Run(tcase.name, func(t *testing.T) {
+			sizer := NewWriteSizer(NewContext(""))
+			tcase.fn(sizer)
+
+			encoder := NewWriteEncoderWithSize(NewContext(""), sizer.Length())
+			tcase.fn(encoder)
+
+			if int(sizer.Length()) != len(encoder.Buffer()) {
+				t.Errorf("Bad length, got: %d, want: %d", sizer.Length(), len(encoder.Buffer()))
+			}
+			if cap(encoder.Buffer()) != len(encoder.Buffer()) {
+				t.Errorf("Buffer was reallocated, cap: %d, len: %d", cap(encoder.Buffer()), len(encoder.Buffer()))
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// Package polywraptest invokes wrapper modules in go test, without building
+// them to wasm or running a Polywrap client.
+//
+// A Harness stands in for the host of the wrapper: it passes the method,
+// args and env of an invocation to the exported _wrap_invoke function of the
+// wrapper and answers its subinvocations with Go funcs.
+//
+//	h := polywraptest.New(_wrap_invoke)
+//	h.HandleSubinvoke("wrap://ens/dep.eth", "method", func(args []byte) ([]byte, error) {
+//		return msgpack.Marshal("result")
+//	})
+//	var result SampleResult
+//	err := h.Invoke("sampleMethod", map[string]string{"arg": "1"}, nil, &result)
+//
+// The harness installs itself with polywrap.SetHost for the duration of each
+// invocation, so tests using it must not run in parallel. Panics of the
+// wrapper are returned as errors, except under TinyGo, which cannot recover
+// them.
+package polywraptest
+
+import (
+	"fmt"
+
+	"github.com/consideritdone/polywrap-go/polywrap"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack"
+)
+
+// InvokeFunc is the signature of the _wrap_invoke export of a wrapper.
+type InvokeFunc func(methodSize, argsSize, envSize uint32) bool
+
+// Handler answers a subinvocation with its msgpack-encoded result, or with an
+// error that the wrapper gets back from WrapSubinvoke.
+type Handler func(args []byte) ([]byte, error)
+
+// InvokeError is the error a wrapper reported for an invocation, or the panic
+// that aborted it.
+type InvokeError struct {
+	Method  string
+	Message string
+	// Panic is set if the wrapper panicked instead of returning an error.
+	Panic bool
+}
+
+func (e *InvokeError) Error() string {
+	if e.Panic {
+		return "invoke of " + e.Method + " panicked: " + e.Message
+	}
+	return e.Message
+}
+
+type handlerKey struct {
+	uri, method string
+}
+
+// Harness invokes the methods of a wrapper. The zero value is not usable,
+// create one with New.
+type Harness struct {
+	invoke          InvokeFunc
+	subinvokes      map[handlerKey]Handler
+	implementations map[handlerKey]Handler
+}
+
+// New returns a Harness invoking methods through fn, the _wrap_invoke export
+// of the wrapper.
+func New(fn InvokeFunc) *Harness {
+	return &Harness{
+		invoke:          fn,
+		subinvokes:      make(map[handlerKey]Handler),
+		implementations: make(map[handlerKey]Handler),
+	}
+}
+
+// HandleSubinvoke makes fn answer the subinvocations of method on uri.
+// Subinvocations without a handler fail.
+func (h *Harness) HandleSubinvoke(uri, method string, fn Handler) {
+	h.subinvokes[handlerKey{uri, method}] = fn
+}
+
+// HandleImplementation makes fn answer the subinvocations of method on the
+// implementation implUri, whatever interface they go through.
+func (h *Harness) HandleImplementation(implUri, method string, fn Handler) {
+	h.implementations[handlerKey{implUri, method}] = fn
+}
+
+// Invoke encodes args and env with msgpack.Marshal, invokes method and
+// decodes its result into result with msgpack.Unmarshal. A nil env invokes
+// the method without env and a nil result discards the result.
+func (h *Harness) Invoke(method string, args, env, result interface{}) error {
+	argsBuf, err := msgpack.Marshal(args)
+	if err != nil {
+		return err
+	}
+	var envBuf []byte
+	if env != nil {
+		if envBuf, err = msgpack.Marshal(env); err != nil {
+			return err
+		}
+	}
+	resultBuf, err := h.InvokeRaw(method, argsBuf, envBuf)
+	if err != nil || result == nil {
+		return err
+	}
+	return msgpack.Unmarshal(resultBuf, result)
+}
+
+// InvokeJSON invokes method with args and env given as JSON, like the ones of
+// a Polywrap client, and returns the result as JSON. A nil env invokes the
+// method without env.
+func (h *Harness) InvokeJSON(method string, args, env []byte) ([]byte, error) {
+	argsBuf, err := msgpack.FromJSON(args)
+	if err != nil {
+		return nil, err
+	}
+	var envBuf []byte
+	if env != nil {
+		if envBuf, err = msgpack.FromJSON(env); err != nil {
+			return nil, err
+		}
+	}
+	resultBuf, err := h.InvokeRaw(method, argsBuf, envBuf)
+	if err != nil {
+		return nil, err
+	}
+	return msgpack.ToJSON(resultBuf)
+}
+
+// InvokeRaw invokes method with msgpack-encoded args and env and returns the
+// msgpack-encoded result. An empty env invokes the method without env. The
+// error is an *InvokeError if the wrapper failed.
+func (h *Harness) InvokeRaw(method string, args, env []byte) (result []byte, err error) {
+	inv := &invocation{harness: h, method: method, args: args, env: env}
+	previous := polywrap.SetHost(inv)
+	defer polywrap.SetHost(previous)
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, &InvokeError{Method: method, Message: fmt.Sprint(r), Panic: true}
+		}
+	}()
+
+	if !h.invoke(uint32(len(method)), uint32(len(args)), uint32(len(env))) {
+		message := inv.error
+		if !inv.failed {
+			message = "Invoke of " + method + " failed without an error"
+		}
+		return nil, &InvokeError{Method: method, Message: message}
+	}
+	if !inv.returned {
+		return nil, &InvokeError{Method: method, Message: "Invoke of " + method + " returned no result"}
+	}
+	return inv.result, nil
+}
+
+// invocation is the polywrap.Host of a single invocation.
+type invocation struct {
+	harness    *Harness
+	method     string
+	args, env  []byte
+	result     []byte
+	returned   bool
+	error      string
+	failed     bool
+	subResult  []byte
+	subError   string
+	implResult []byte
+	implError  string
+}
+
+func (inv *invocation) InvokeArgs(method, args []byte) {
+	copy(method, inv.method)
+	copy(args, inv.args)
+}
+
+func (inv *invocation) InvokeResult(result []byte) {
+	inv.result = append([]byte(nil), result...)
+	inv.returned = true
+}
+
+func (inv *invocation) InvokeError(message string) {
+	inv.error = message
+	inv.failed = true
+}
+
+// call runs the handler of key and returns its result or error message.
+func call(handlers map[handlerKey]Handler, key handlerKey, args []byte) ([]byte, string, bool) {
+	fn, ok := handlers[key]
+	if !ok {
+		return nil, "No handler for method " + key.method + " of " + key.uri, false
+	}
+	result, err := fn(append([]byte(nil), args...))
+	if err != nil {
+		return nil, err.Error(), false
+	}
+	return result, "", true
+}
+
+func (inv *invocation) Subinvoke(uri, method string, args []byte) bool {
+	var ok bool
+	inv.subResult, inv.subError, ok = call(inv.harness.subinvokes, handlerKey{uri, method}, args)
+	return ok
+}
+
+func (inv *invocation) SubinvokeResultLen() uint32 {
+	return uint32(len(inv.subResult))
+}
+
+func (inv *invocation) SubinvokeResult(result []byte) {
+	copy(result, inv.subResult)
+}
+
+func (inv *invocation) SubinvokeErrorLen() uint32 {
+	return uint32(len(inv.subError))
+}
+
+func (inv *invocation) SubinvokeError(message []byte) {
+	copy(message, inv.subError)
+}
+
+func (inv *invocation) SubinvokeImplementation(interfaceUri, implUri, method string, args []byte) bool {
+	var ok bool
+	inv.implResult, inv.implError, ok = call(inv.harness.implementations, handlerKey{implUri, method}, args)
+	return ok
+}
+
+func (inv *invocation) SubinvokeImplementationResultLen() uint32 {
+	return uint32(len(inv.implResult))
+}
+
+func (inv *invocation) SubinvokeImplementationResult(result []byte) {
+	copy(result, inv.implResult)
+}
+
+func (inv *invocation) SubinvokeImplementationErrorLen() uint32 {
+	return uint32(len(inv.implError))
+}
+
+func (inv *invocation) SubinvokeImplementationError(message []byte) {
+	copy(message, inv.implError)
+}
+
+func (inv *invocation) LoadEnv(env []byte) {
+	copy(env, inv.env)
+}
@@ -0,0 +1,177 @@
+package polywraptest
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"github.com/consideritdone/polywrap-go/polywrap"
+	"github.com/consideritdone/polywrap-go/polywrap/msgpack"
+)
+
+type testArgs struct {
+	Name string
+}
+
+type testEnv struct {
+	Greeting string
+}
+
+// _wrap_invoke is a wrapper whose methods subinvoke "wrap://ens/dep.eth"
+func _wrap_invoke(methodSize, argsSize, envSize uint32) bool {
+	args := polywrap.WrapInvokeArgs(methodSize, argsSize)
+
+	switch args.Method {
+	case "greet":
+		return polywrap.WrapInvoke(args, envSize, greet)
+	case "subinvoke":
+		return polywrap.WrapInvoke(args, envSize, func(argsBuf []byte, envSize uint32) []byte {
+			result, err := polywrap.WrapSubinvoke("wrap://ens/dep.eth", "method", argsBuf)
+			if err != nil {
+				panic(err.Error())
+			}
+			return result
+		})
+	case "implementation":
+		return polywrap.WrapInvoke(args, envSize, func(argsBuf []byte, envSize uint32) []byte {
+			result, err := polywrap.WrapSubinvokeImplementation("wrap://ens/interface.eth", "wrap://ens/impl.eth", "method", argsBuf)
+			if err != nil {
+				panic(err.Error())
+			}
+			return result
+		})
+	}
+	return polywrap.WrapInvoke(args, envSize, nil)
+}
+
+func greet(argsBuf []byte, envSize uint32) []byte {
+	var args testArgs
+	if err := msgpack.Unmarshal(argsBuf, &args); err != nil {
+		panic(err.Error())
+	}
+	env := testEnv{Greeting: "Hello"}
+	if err := polywrap.UnmarshalEnv(envSize, &env); err != nil && err != polywrap.ErrEnvNotSet {
+		panic(err.Error())
+	}
+	result, err := msgpack.Marshal(env.Greeting + ", " + args.Name)
+	if err != nil {
+		panic(err.Error())
+	}
+	return result
+}
+
+func TestInvoke(t *testing.T) {
+	h := New(_wrap_invoke)
+
+	tests := []struct {
+		name   string
+		env    interface{}
+		result string
+	}{
+		{name: "without env", result: "Hello, Go"},
+		{name: "with env", env: testEnv{Greeting: "Hi"}, result: "Hi, Go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result string
+			if err := h.Invoke("greet", testArgs{Name: "Go"}, tt.env, &result); err != nil {
+				t.Fatalf("Invoke failed: %v", err)
+			}
+			if result != tt.result {
+				t.Errorf("Bad result, got: %q, want: %q", result, tt.result)
+			}
+		})
+	}
+}
+
+func TestInvokeJSON(t *testing.T) {
+	h := New(_wrap_invoke)
+
+	result, err := h.InvokeJSON("greet", []byte(`{"name":"JSON"}`), []byte(`{"greeting":"Hey"}`))
+	if err != nil {
+		t.Fatalf("InvokeJSON failed: %v", err)
+	}
+	if want := `"Hey, JSON"`; string(result) != want {
+		t.Errorf("Bad result, got: %s, want: %s", result, want)
+	}
+}
+
+func TestInvokeErrors(t *testing.T) {
+	h := New(_wrap_invoke)
+
+	tests := []struct {
+		name   string
+		method string
+		args   interface{}
+		err    InvokeError
+	}{
+		{
+			name:   "unknown method",
+			method: "unknown",
+			err:    InvokeError{Method: "unknown", Message: "Could not find invoke function \"unknown\""},
+		},
+		{
+			name:   "panic",
+			method: "greet",
+			args:   []string{"not", "a", "map"},
+			err:    InvokeError{Method: "greet", Panic: true},
+		},
+		{
+			name:   "missing subinvoke handler",
+			method: "subinvoke",
+			err:    InvokeError{Method: "subinvoke", Message: "No handler for method method of wrap://ens/dep.eth", Panic: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err.Panic && runtime.Compiler == "tinygo" {
+				t.Log("Skipping due tinygo limitations")
+				return
+			}
+			err := h.Invoke(tt.method, tt.args, nil, nil)
+			var invokeErr *InvokeError
+			if !errors.As(err, &invokeErr) {
+				t.Fatalf("Bad error, got: %v, want: an *InvokeError", err)
+			}
+			if invokeErr.Method != tt.err.Method || invokeErr.Panic != tt.err.Panic ||
+				tt.err.Message != "" && invokeErr.Message != tt.err.Message {
+				t.Errorf("Bad error, got: %+v, want: %+v", *invokeErr, tt.err)
+			}
+		})
+	}
+}
+
+func TestSubinvoke(t *testing.T) {
+	h := New(_wrap_invoke)
+
+	var subArgs testArgs
+	h.HandleSubinvoke("wrap://ens/dep.eth", "method", func(args []byte) ([]byte, error) {
+		if err := msgpack.Unmarshal(args, &subArgs); err != nil {
+			return nil, err
+		}
+		return msgpack.Marshal("dep result")
+	})
+	h.HandleImplementation("wrap://ens/impl.eth", "method", func(args []byte) ([]byte, error) {
+		return nil, errors.New("impl failed")
+	})
+
+	var result string
+	if err := h.Invoke("subinvoke", testArgs{Name: "sub"}, nil, &result); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	if result != "dep result" || subArgs.Name != "sub" {
+		t.Errorf("Bad subinvoke, got: %q %+v, want: \"dep result\" {Name:sub}", result, subArgs)
+	}
+
+	if runtime.Compiler == "tinygo" {
+		t.Log("Skipping due tinygo limitations")
+		return
+	}
+	err := h.Invoke("implementation", testArgs{}, nil, nil)
+	var invokeErr *InvokeError
+	if !errors.As(err, &invokeErr) || invokeErr.Message != "impl failed" {
+		t.Errorf("Bad error, got: %v, want: impl failed", err)
+	}
+}